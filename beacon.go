@@ -0,0 +1,310 @@
+package vast
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults used by a Beaconer for the zero values of BeaconOptions.
+const (
+	DefaultBeaconWorkers     = 4
+	DefaultBeaconMaxAttempts = 3
+	DefaultBeaconBackoff     = 250 * time.Millisecond
+	DefaultBeaconDedupSize   = 1000
+)
+
+// BeaconOptions configures a Beaconer.
+type BeaconOptions struct {
+	// Transport is used to send the beacons. If nil, http.DefaultTransport is
+	// used. Redirects are followed.
+	Transport http.RoundTripper
+	// The number of beacons sent concurrently
+	Workers int
+	// The maximum number of requests sent for a beacon, including retries.
+	// Set it to 1 to disable retries.
+	MaxAttempts int
+	// The delay before the first retry of a beacon, doubled on each retry
+	Backoff time.Duration
+	// If true, a URI fired more than once is only sent the first time. Only
+	// the last DedupSize URIs are remembered.
+	Dedup     bool
+	DedupSize int
+
+	// The User-Agent of the device the ad is played on. As recommended by the
+	// IAB guidelines for server-side ad insertion, it is sent both as the
+	// User-Agent and X-Device-User-Agent headers.
+	UserAgent string
+	// The IP address of the device the ad is played on, sent as the
+	// X-Device-IP header
+	DeviceIP string
+	// The X-Forwarded-For header, usually the IP address of the device
+	ForwardedFor string
+
+	// If not nil, the beacons are persisted to Queue until they are delivered
+	// and the beacons left pending by a previous run are sent again.
+	Queue *BeaconQueue
+
+	// If not nil, the result of each beacon is sent to Results. The channel
+	// must be drained as the workers block until their result is received.
+	// It is not closed by the Beaconer.
+	Results chan<- BeaconResult
+}
+
+// BeaconResult reports the outcome of a beacon for auditing
+type BeaconResult struct {
+	URI string
+	// The status code of the last response, 0 if none was received
+	StatusCode int
+	// The number of requests sent
+	Attempts int
+	// The error of the last attempt, nil if the beacon was received
+	Err error
+	// True if the beacon was not sent because the URI was already fired
+	Duplicate bool
+	// The error persisting the beacon to the Queue or marking it as
+	// delivered, if any. The beacon is then not sent on the next run if it
+	// fails, or may be sent again if it was delivered.
+	QueueErr error
+}
+
+// queuedBeacon is a beacon waiting for a worker
+type queuedBeacon struct {
+	QueuedBeacon
+	// The error persisting the beacon, if any
+	queueErr error
+}
+
+// Beaconer fires the tracking URIs of an ad session, such as impressions,
+// tracking events and click trackings, using a bounded pool of workers.
+//
+// Failed beacons are retried with an exponential backoff on network errors
+// and 5xx or 429 responses. The [TIMESTAMP] macro of the URIs is expanded to
+// the time they are fired. A Beaconer is safe for concurrent use.
+type Beaconer struct {
+	opts   BeaconOptions
+	ctx    context.Context
+	client *http.Client
+	queue  chan queuedBeacon
+	wg     sync.WaitGroup
+	replay sync.WaitGroup
+	// The Fire calls queuing a beacon
+	fires sync.WaitGroup
+
+	mu sync.Mutex
+	// The URIs already fired, in the order they were fired, if Dedup is set
+	seen      map[string]bool
+	seenOrder []string
+	closed    bool
+}
+
+// NewBeaconer starts the workers of a beaconer. Pending and future beacons
+// fail once ctx is done.
+func NewBeaconer(ctx context.Context, opts BeaconOptions) *Beaconer {
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultBeaconWorkers
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultBeaconMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBeaconBackoff
+	}
+	if opts.DedupSize <= 0 {
+		opts.DedupSize = DefaultBeaconDedupSize
+	}
+	b := &Beaconer{
+		opts:   opts,
+		ctx:    ctx,
+		client: &http.Client{Transport: opts.Transport},
+		queue:  make(chan queuedBeacon),
+		seen:   map[string]bool{},
+	}
+	b.wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go b.work()
+	}
+	if opts.Queue != nil {
+		// Take the pending beacons before new ones can be fired
+		pending := opts.Queue.Pending()
+		b.replay.Add(1)
+		go func() {
+			defer b.replay.Done()
+			for _, qb := range pending {
+				b.enqueue(queuedBeacon{QueuedBeacon: qb})
+			}
+		}()
+	}
+	return b
+}
+
+// Fire queues the given URIs, blocking until a worker is available for each
+// of them. Empty URIs are ignored, as well as all URIs once Close is called.
+func (b *Beaconer) Fire(uris ...string) {
+	for _, uri := range uris {
+		uri = strings.TrimSpace(uri)
+		if uri == "" {
+			continue
+		}
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			return
+		}
+		dup := b.opts.Dedup && b.remember(uri)
+		b.fires.Add(1)
+		b.mu.Unlock()
+
+		if dup {
+			b.report(BeaconResult{URI: uri, Duplicate: true})
+		} else {
+			qb := queuedBeacon{QueuedBeacon: QueuedBeacon{URI: uri, Time: time.Now()}}
+			if b.opts.Queue != nil {
+				// The beacon is still sent if it can't be persisted
+				if pqb, err := b.opts.Queue.add(uri, qb.Time); err != nil {
+					qb.queueErr = err
+				} else {
+					qb.QueuedBeacon = pqb
+				}
+			}
+			b.enqueue(qb)
+		}
+		b.fires.Done()
+	}
+}
+
+// remember adds uri to the fired URIs, forgetting the oldest one if there are
+// more than DedupSize, and tells if it was already fired. b.mu must be held.
+func (b *Beaconer) remember(uri string) bool {
+	if b.seen[uri] {
+		return true
+	}
+	b.seen[uri] = true
+	b.seenOrder = append(b.seenOrder, uri)
+	if len(b.seenOrder) > b.opts.DedupSize {
+		delete(b.seen, b.seenOrder[0])
+		b.seenOrder = b.seenOrder[1:]
+	}
+	return false
+}
+
+// enqueue waits for a worker to send the beacon
+func (b *Beaconer) enqueue(qb queuedBeacon) {
+	select {
+	case b.queue <- qb:
+	case <-b.ctx.Done():
+		b.report(BeaconResult{URI: qb.URI, Err: b.ctx.Err(), QueueErr: qb.queueErr})
+	}
+}
+
+// Close waits for the queued beacons to be sent and stops the workers. The
+// Queue, if any, is not closed.
+func (b *Beaconer) Close() {
+	b.replay.Wait()
+	b.mu.Lock()
+	closed := b.closed
+	b.closed = true
+	b.mu.Unlock()
+	if !closed {
+		b.fires.Wait()
+		close(b.queue)
+	}
+	b.wg.Wait()
+}
+
+func (b *Beaconer) work() {
+	defer b.wg.Done()
+	for qb := range b.queue {
+		r, retry := b.send(qb.QueuedBeacon)
+		r.QueueErr = qb.queueErr
+		if qb.ID != 0 && !retry {
+			r.QueueErr = b.opts.Queue.done(qb.ID)
+		}
+		b.report(r)
+	}
+}
+
+// send sends the beacon, retrying it if needed. It tells if the beacon failed
+// but could be delivered later.
+func (b *Beaconer) send(qb QueuedBeacon) (r BeaconResult, retry bool) {
+	r.URI = qb.URI
+	uri := expandTimestamp(qb.URI, qb.Time)
+	backoff := b.opts.Backoff
+	for r.Attempts < b.opts.MaxAttempts {
+		if r.Attempts > 0 {
+			t := time.NewTimer(backoff)
+			select {
+			case <-t.C:
+			case <-b.ctx.Done():
+				t.Stop()
+				r.Err = b.ctx.Err()
+				return r, true
+			}
+			backoff *= 2
+		}
+		r.Attempts++
+		r.StatusCode, retry, r.Err = b.request(uri)
+		if !retry {
+			break
+		}
+	}
+	return r, retry
+}
+
+// request sends a single request for the beacon and tells if it should be
+// retried on failure
+func (b *Beaconer) request(uri string) (status int, retry bool, err error) {
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	if b.opts.UserAgent != "" {
+		req.Header.Set("User-Agent", b.opts.UserAgent)
+		req.Header.Set("X-Device-User-Agent", b.opts.UserAgent)
+	}
+	if b.opts.DeviceIP != "" {
+		req.Header.Set("X-Device-IP", b.opts.DeviceIP)
+	}
+	if b.opts.ForwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", b.opts.ForwardedFor)
+	}
+	res, err := b.client.Do(req.WithContext(b.ctx))
+	if err != nil {
+		return 0, b.ctx.Err() == nil, err
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+	if res.StatusCode >= 400 {
+		retry = res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+		return res.StatusCode, retry, fmt.Errorf("vast: unexpected status %d firing %s", res.StatusCode, uri)
+	}
+	return res.StatusCode, false, nil
+}
+
+func (b *Beaconer) report(r BeaconResult) {
+	if b.opts.Results != nil {
+		b.opts.Results <- r
+	}
+}
+
+// expandTimestamp expands the [TIMESTAMP] macro of uri to t, leaving the
+// other macros intact
+func expandTimestamp(uri string, t time.Time) string {
+	var m Macros
+	m.SetTimestamp(t)
+	ts, _ := m.Get(MacroTimestamp)
+	return macroRe.ReplaceAllStringFunc(uri, func(match string) string {
+		if strings.Trim(match, "[]%") != MacroTimestamp {
+			return match
+		}
+		return encodeMacroValue(ts)
+	})
+}
@@ -0,0 +1,184 @@
+package vast
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// beaconServer counts the requests received per path. /flaky fails on the
+// first request, /down always fails and /missing is not found.
+type beaconServer struct {
+	mu        sync.Mutex
+	hits      map[string]int
+	headers   http.Header
+	onRequest func(r *http.Request)
+}
+
+func (s *beaconServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.hits[r.URL.Path]++
+	n := s.hits[r.URL.Path]
+	s.headers = r.Header
+	if s.onRequest != nil {
+		s.onRequest(r)
+	}
+	s.mu.Unlock()
+	switch r.URL.Path {
+	case "/flaky":
+		if n < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	case "/down":
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	case "/missing":
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case "/redirect":
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func collectResults(results chan BeaconResult) (map[string]BeaconResult, *sync.WaitGroup, *[]BeaconResult) {
+	byURI := map[string]BeaconResult{}
+	all := []BeaconResult{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for r := range results {
+			all = append(all, r)
+			if !r.Duplicate {
+				byURI[r.URI] = r
+			}
+		}
+	}()
+	return byURI, &wg, &all
+}
+
+func TestBeaconer(t *testing.T) {
+	srv := &beaconServer{hits: map[string]int{}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	results := make(chan BeaconResult)
+	byURI, wg, all := collectResults(results)
+	b := NewBeaconer(context.Background(), BeaconOptions{
+		Workers:      2,
+		Backoff:      time.Millisecond,
+		Dedup:        true,
+		UserAgent:    "Player/1.0",
+		DeviceIP:     "203.0.113.7",
+		ForwardedFor: "203.0.113.7",
+		Results:      results,
+	})
+	b.Fire(ts.URL+"/impression", " "+ts.URL+"/impression ", "", ts.URL+"/flaky")
+	b.Fire(ts.URL+"/down", ts.URL+"/missing", ts.URL+"/redirect")
+	b.Close()
+	b.Fire(ts.URL + "/late")
+	close(results)
+	wg.Wait()
+
+	assert.Len(t, *all, 6)
+	assert.Equal(t, BeaconResult{URI: ts.URL + "/impression", StatusCode: 204, Attempts: 1}, byURI[ts.URL+"/impression"])
+	assert.Equal(t, BeaconResult{URI: ts.URL + "/flaky", StatusCode: 204, Attempts: 2}, byURI[ts.URL+"/flaky"])
+	assert.Equal(t, 3, byURI[ts.URL+"/down"].Attempts)
+	assert.EqualError(t, byURI[ts.URL+"/down"].Err, "vast: unexpected status 500 firing "+ts.URL+"/down")
+	assert.Equal(t, 1, byURI[ts.URL+"/missing"].Attempts)
+	assert.Equal(t, 404, byURI[ts.URL+"/missing"].StatusCode)
+	assert.Equal(t, BeaconResult{URI: ts.URL + "/redirect", StatusCode: 204, Attempts: 1}, byURI[ts.URL+"/redirect"])
+
+	var paths []string
+	for p := range srv.hits {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	assert.Equal(t, []string{"/down", "/elsewhere", "/flaky", "/impression", "/missing", "/redirect"}, paths)
+	assert.Equal(t, 1, srv.hits["/impression"])
+	assert.Equal(t, "Player/1.0", srv.headers.Get("User-Agent"))
+	assert.Equal(t, "Player/1.0", srv.headers.Get("X-Device-User-Agent"))
+	assert.Equal(t, "203.0.113.7", srv.headers.Get("X-Device-IP"))
+	assert.Equal(t, "203.0.113.7", srv.headers.Get("X-Forwarded-For"))
+}
+
+func TestBeaconerDedup(t *testing.T) {
+	srv := &beaconServer{hits: map[string]int{}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	// URIs are sent each time they are fired by default
+	b := NewBeaconer(context.Background(), BeaconOptions{Workers: 1})
+	b.Fire(ts.URL+"/pause", ts.URL+"/pause")
+	b.Close()
+	assert.Equal(t, 2, srv.hits["/pause"])
+
+	// Only the last DedupSize URIs are remembered
+	b = NewBeaconer(context.Background(), BeaconOptions{Workers: 1, Dedup: true, DedupSize: 1})
+	b.Fire(ts.URL+"/a", ts.URL+"/a", ts.URL+"/b", ts.URL+"/a")
+	b.Close()
+	assert.Equal(t, 2, srv.hits["/a"])
+	assert.Equal(t, 1, srv.hits["/b"])
+	assert.Len(t, b.seen, 1)
+}
+
+func TestBeaconerConcurrentFire(t *testing.T) {
+	srv := &beaconServer{hits: map[string]int{}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	// Fire calls are not serialized while waiting for the results to be read
+	results := make(chan BeaconResult)
+	b := NewBeaconer(context.Background(), BeaconOptions{Workers: 2, Dedup: true, Results: results})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Fire(ts.URL + "/impression")
+		}()
+	}
+	n := 0
+	for n < 4 {
+		<-results
+		n++
+	}
+	wg.Wait()
+	b.Close()
+	assert.Equal(t, 1, srv.hits["/impression"])
+}
+
+func TestBeaconerCancel(t *testing.T) {
+	srv := &beaconServer{hits: map[string]int{}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan BeaconResult, 10)
+	b := NewBeaconer(ctx, BeaconOptions{Workers: 1, Backoff: time.Hour, Results: results})
+	b.Fire(ts.URL + "/down")
+	cancel()
+	b.Fire(ts.URL + "/impression")
+	b.Close()
+	close(results)
+
+	var errs []error
+	for r := range results {
+		errs = append(errs, r.Err)
+	}
+	if assert.Len(t, errs, 2) {
+		for _, err := range errs {
+			assert.Error(t, err)
+		}
+	}
+	assert.Equal(t, 0, srv.hits["/impression"])
+}
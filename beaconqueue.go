@@ -0,0 +1,174 @@
+package vast
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// QueuedBeacon is a beacon persisted by a BeaconQueue until it is delivered
+type QueuedBeacon struct {
+	ID uint64 `json:"id"`
+	// The URI of the beacon, with its [TIMESTAMP] macro not expanded
+	URI string `json:"uri,omitempty"`
+	// The time the beacon was fired, used to expand [TIMESTAMP] on delivery
+	Time time.Time `json:"time"`
+	// Set in the records marking a beacon as delivered
+	Done bool `json:"done,omitempty"`
+}
+
+// BeaconQueue persists the beacons of a Beaconer to an append-only file, so
+// beacons not delivered when the process stops, e.g. because of a network
+// outage, are sent again when it restarts.
+//
+// A record is appended when a beacon is fired and when it is delivered or
+// rejected by the server. Beacons failing because of network errors or server
+// errors are kept for the next run. The file is compacted when opened.
+type BeaconQueue struct {
+	mu      sync.Mutex
+	f       *os.File
+	nextID  uint64
+	pending map[uint64]QueuedBeacon
+	maxAge  time.Duration
+	now     func() time.Time
+}
+
+// OpenBeaconQueue opens the queue stored in the file at path, creating it if
+// needed. Beacons fired more than maxAge ago are dropped; if maxAge is zero,
+// beacons never expire.
+func OpenBeaconQueue(path string, maxAge time.Duration) (*BeaconQueue, error) {
+	return openBeaconQueue(path, maxAge, time.Now)
+}
+
+func openBeaconQueue(path string, maxAge time.Duration, now func() time.Time) (*BeaconQueue, error) {
+	q := &BeaconQueue{nextID: 1, pending: map[uint64]QueuedBeacon{}, maxAge: maxAge, now: now}
+	if err := q.load(path); err != nil {
+		return nil, err
+	}
+	// Compact the file to the beacons still pending
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, qb := range q.Pending() {
+		if err := enc.Encode(qb); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+	if q.f, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// load reads the records of the file at path. Invalid records, such as a last
+// record partially written during a crash, are ignored.
+func (q *BeaconQueue) load(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		var qb QueuedBeacon
+		if err := json.Unmarshal(s.Bytes(), &qb); err != nil || qb.ID == 0 {
+			continue
+		}
+		if qb.ID >= q.nextID {
+			q.nextID = qb.ID + 1
+		}
+		if qb.Done {
+			delete(q.pending, qb.ID)
+		} else if !q.expired(qb) {
+			q.pending[qb.ID] = qb
+		}
+	}
+	return s.Err()
+}
+
+func (q *BeaconQueue) expired(qb QueuedBeacon) bool {
+	return q.maxAge > 0 && q.now().Sub(qb.Time) > q.maxAge
+}
+
+// Pending returns the beacons not delivered yet and not expired, in the order
+// they were fired.
+func (q *BeaconQueue) Pending() []QueuedBeacon {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var pending []QueuedBeacon
+	for _, qb := range q.pending {
+		if !q.expired(qb) {
+			pending = append(pending, qb)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ID < pending[j].ID
+	})
+	return pending
+}
+
+// Close closes the queue file.
+func (q *BeaconQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.f.Close()
+}
+
+// add persists a fired beacon
+func (q *BeaconQueue) add(uri string, t time.Time) (QueuedBeacon, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	qb := QueuedBeacon{ID: q.nextID, URI: uri, Time: t}
+	q.nextID++
+	if err := q.write(qb); err != nil {
+		return qb, err
+	}
+	if err := q.f.Sync(); err != nil {
+		return qb, err
+	}
+	q.pending[qb.ID] = qb
+	return qb, nil
+}
+
+// done marks a beacon as delivered
+func (q *BeaconQueue) done(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.pending, id)
+	return q.write(QueuedBeacon{ID: id, Done: true})
+}
+
+func (q *BeaconQueue) write(qb QueuedBeacon) error {
+	b, err := json.Marshal(qb)
+	if err != nil {
+		return err
+	}
+	_, err = q.f.Write(append(b, '\n'))
+	return err
+}
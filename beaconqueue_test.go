@@ -0,0 +1,149 @@
+package vast
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// downTransport fails all requests as if the network was down
+type downTransport struct{}
+
+func (downTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network is down")
+}
+
+func TestBeaconerQueue(t *testing.T) {
+	srv := &beaconServer{hits: map[string]int{}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	dir, err := ioutil.TempDir("", "vast")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "beacons")
+
+	// First run: the network is down
+	q, err := OpenBeaconQueue(path, time.Hour)
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := NewBeaconer(context.Background(), BeaconOptions{Transport: downTransport{}, MaxAttempts: 1, Queue: q})
+	b.Fire(ts.URL+"/impression?ts=[TIMESTAMP]", ts.URL+"/start")
+	b.Close()
+	assert.NoError(t, q.Close())
+
+	// Second run: the pending beacons are sent with their original timestamp
+	q, err = OpenBeaconQueue(path, time.Hour)
+	if !assert.NoError(t, err) {
+		return
+	}
+	pending := q.Pending()
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, ts.URL+"/impression?ts=[TIMESTAMP]", pending[0].URI)
+	}
+	queries := map[string]string{}
+	srv.onRequest = func(r *http.Request) { queries[r.URL.Path] = r.URL.RawQuery }
+	b = NewBeaconer(context.Background(), BeaconOptions{Workers: 1, Queue: q})
+	b.Fire(ts.URL + "/complete")
+	b.Close()
+	assert.Equal(t, 1, srv.hits["/impression"])
+	assert.Equal(t, 1, srv.hits["/start"])
+	assert.Equal(t, 1, srv.hits["/complete"])
+	assert.Equal(t, "ts="+expandTimestamp("[TIMESTAMP]", pending[0].Time), queries["/impression"])
+	assert.Len(t, q.Pending(), 0)
+	assert.NoError(t, q.Close())
+
+	// Third run: nothing left to send
+	q, err = OpenBeaconQueue(path, time.Hour)
+	if assert.NoError(t, err) {
+		assert.Len(t, q.Pending(), 0)
+		q.Close()
+	}
+	data, _ := ioutil.ReadFile(path)
+	assert.Len(t, data, 0)
+}
+
+func TestBeaconQueueExpiry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vast")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "beacons")
+
+	now := time.Date(2016, 1, 17, 8, 0, 0, 0, time.UTC)
+	q, err := openBeaconQueue(path, time.Hour, func() time.Time { return now })
+	if !assert.NoError(t, err) {
+		return
+	}
+	q.add("http://t/old", now.Add(-2*time.Hour))
+	q.add("http://t/recent", now.Add(-time.Minute))
+	q.add("http://t/delivered", now)
+	q.done(3)
+	assert.NoError(t, q.Close())
+	// A record partially written during a crash
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString(`{"id":4,"uri":"http://t/partial`)
+	f.Close()
+
+	q, err = openBeaconQueue(path, time.Hour, func() time.Time { return now })
+	if !assert.NoError(t, err) {
+		return
+	}
+	pending := q.Pending()
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, "http://t/recent", pending[0].URI)
+	}
+	now = now.Add(time.Hour)
+	assert.Len(t, q.Pending(), 0)
+	qb, err := q.add("http://t/new", now)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), qb.ID)
+	q.Close()
+}
+
+func TestBeaconerQueueError(t *testing.T) {
+	srv := &beaconServer{hits: map[string]int{}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	dir, err := ioutil.TempDir("", "vast")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	q, err := OpenBeaconQueue(filepath.Join(dir, "beacons"), 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// The queue is closed before the beacon can be marked as delivered
+	srv.onRequest = func(*http.Request) { q.Close() }
+	results := make(chan BeaconResult, 1)
+	b := NewBeaconer(context.Background(), BeaconOptions{Queue: q, Results: results})
+	b.Fire(ts.URL + "/impression")
+	b.Close()
+	r := <-results
+	assert.NoError(t, r.Err)
+	assert.Error(t, r.QueueErr)
+	assert.Equal(t, 1, srv.hits["/impression"])
+
+	// The queue is closed before the beacon can be persisted
+	srv.onRequest = nil
+	b = NewBeaconer(context.Background(), BeaconOptions{Queue: q, Results: results})
+	b.Fire(ts.URL + "/start")
+	b.Close()
+	r = <-results
+	assert.NoError(t, r.Err)
+	assert.Error(t, r.QueueErr)
+	assert.Equal(t, 1, srv.hits["/start"])
+}
@@ -0,0 +1,239 @@
+package vast
+
+import (
+	"fmt"
+	"time"
+)
+
+// InLineBuilder builds a VAST document containing a single InLine ad.
+//
+// Creatives are added with LinearCreative, NonLinearCreative and CompanionCreative.
+// Creative level methods like Tracking or MediaFile apply to the last added creative.
+type InLineBuilder struct {
+	vast     *VAST
+	inline   *InLine
+	creative *Creative
+	err      error
+}
+
+// NewInLine starts building a VAST 3.0 document with a single InLine ad.
+func NewInLine(id, adSystem, adTitle string) *InLineBuilder {
+	in := &InLine{AdSystem: &AdSystem{Name: adSystem}, AdTitle: adTitle}
+	return &InLineBuilder{
+		vast:   &VAST{Version: "3.0", Ads: []*Ad{{ID: id, InLine: in}}},
+		inline: in,
+	}
+}
+
+// Version sets the VAST version of the document.
+func (b *InLineBuilder) Version(version string) *InLineBuilder {
+	b.vast.Version = version
+	return b
+}
+
+// Sequence sets the sequence of the ad in a pod.
+func (b *InLineBuilder) Sequence(seq int) *InLineBuilder {
+	b.vast.Ads[0].Sequence = seq
+	return b
+}
+
+// AdSystemVersion sets the version of the ad server that returned the ad.
+func (b *InLineBuilder) AdSystemVersion(version string) *InLineBuilder {
+	b.inline.AdSystem.Version = version
+	return b
+}
+
+// AdServingID sets the identifier used to compare impression-level data
+// across systems, required since VAST 4.1.
+func (b *InLineBuilder) AdServingID(id string) *InLineBuilder {
+	b.inline.AdServingID = id
+	return b
+}
+
+// Description sets the description of the ad.
+func (b *InLineBuilder) Description(desc string) *InLineBuilder {
+	b.inline.Description = desc
+	return b
+}
+
+// Advertiser sets the name of the advertiser.
+func (b *InLineBuilder) Advertiser(name string) *InLineBuilder {
+	b.inline.Advertiser = name
+	return b
+}
+
+// Error adds an error URI to the ad.
+func (b *InLineBuilder) Error(uri string) *InLineBuilder {
+	b.inline.Errors = append(b.inline.Errors, uri)
+	return b
+}
+
+// Impression adds an impression URI to the ad.
+func (b *InLineBuilder) Impression(uri string) *InLineBuilder {
+	return b.ImpressionWithID("", uri)
+}
+
+// ImpressionWithID adds an identified impression URI to the ad.
+func (b *InLineBuilder) ImpressionWithID(id, uri string) *InLineBuilder {
+	b.inline.Impressions = append(b.inline.Impressions, &Impression{ID: id, URI: uri})
+	return b
+}
+
+// LinearCreative adds a linear creative of the given duration.
+func (b *InLineBuilder) LinearCreative(dur time.Duration) *InLineBuilder {
+	d := Duration(dur)
+	return b.addCreative(&Creative{Linear: &Linear{Duration: &d}})
+}
+
+// NonLinearCreative adds a non-linear creative.
+func (b *InLineBuilder) NonLinearCreative() *InLineBuilder {
+	return b.addCreative(&Creative{NonLinearAds: &NonLinearAds{}})
+}
+
+// CompanionCreative adds a companion creative. Required is either "all", "any",
+// "none" or empty.
+func (b *InLineBuilder) CompanionCreative(required string) *InLineBuilder {
+	return b.addCreative(&Creative{CompanionAds: &CompanionAds{Required: required}})
+}
+
+func (b *InLineBuilder) addCreative(c *Creative) *InLineBuilder {
+	b.inline.Creatives = append(b.inline.Creatives, c)
+	b.creative = c
+	return b
+}
+
+// fail records the first misuse of the builder, reported by Build
+func (b *InLineBuilder) fail(format string, args ...interface{}) *InLineBuilder {
+	if b.err == nil {
+		b.err = fmt.Errorf("vast: "+format, args...)
+	}
+	return b
+}
+
+// AdID sets the AdID of the current creative.
+func (b *InLineBuilder) AdID(adID string) *InLineBuilder {
+	if b.creative == nil {
+		return b.fail("AdID called before adding a creative")
+	}
+	b.creative.AdID = adID
+	return b
+}
+
+// UniversalAdID adds a universal identifier of the current creative in the
+// given registry, required since VAST 4.0.
+func (b *InLineBuilder) UniversalAdID(registry, id string) *InLineBuilder {
+	if b.creative == nil {
+		return b.fail("UniversalAdID called before adding a creative")
+	}
+	b.creative.UniversalAdIDs = append(b.creative.UniversalAdIDs, &UniversalAdID{IDRegistry: registry, ID: id})
+	return b
+}
+
+// SkipOffset makes the current linear creative skippable after offset.
+func (b *InLineBuilder) SkipOffset(offset Offset) *InLineBuilder {
+	if b.creative == nil || b.creative.Linear == nil {
+		return b.fail("SkipOffset called outside of a linear creative")
+	}
+	b.creative.Linear.SkipOffset = &offset
+	return b
+}
+
+// MediaFile adds a media file to the current linear creative.
+func (b *InLineBuilder) MediaFile(mf *MediaFile) *InLineBuilder {
+	if b.creative == nil || b.creative.Linear == nil {
+		return b.fail("MediaFile called outside of a linear creative")
+	}
+	b.creative.Linear.MediaFiles = append(b.creative.Linear.MediaFiles, mf)
+	return b
+}
+
+// ClickThrough sets the click through URI of the current linear creative.
+func (b *InLineBuilder) ClickThrough(uri string) *InLineBuilder {
+	vc := b.videoClicks("ClickThrough")
+	if vc != nil {
+		vc.ClickThroughs = append(vc.ClickThroughs, &VideoClick{URI: uri})
+	}
+	return b
+}
+
+// ClickTracking adds a click tracking URI to the current linear creative.
+func (b *InLineBuilder) ClickTracking(uri string) *InLineBuilder {
+	vc := b.videoClicks("ClickTracking")
+	if vc != nil {
+		vc.ClickTrackings = append(vc.ClickTrackings, &VideoClick{URI: uri})
+	}
+	return b
+}
+
+func (b *InLineBuilder) videoClicks(caller string) *VideoClicks {
+	if b.creative == nil || b.creative.Linear == nil {
+		b.fail("%s called outside of a linear creative", caller)
+		return nil
+	}
+	if b.creative.Linear.VideoClicks == nil {
+		b.creative.Linear.VideoClicks = &VideoClicks{}
+	}
+	return b.creative.Linear.VideoClicks
+}
+
+// NonLinear adds a non-linear ad to the current non-linear creative.
+func (b *InLineBuilder) NonLinear(nl NonLinear) *InLineBuilder {
+	if b.creative == nil || b.creative.NonLinearAds == nil {
+		return b.fail("NonLinear called outside of a non-linear creative")
+	}
+	b.creative.NonLinearAds.NonLinears = append(b.creative.NonLinearAds.NonLinears, nl)
+	return b
+}
+
+// Companion adds a companion to the current companion creative.
+func (b *InLineBuilder) Companion(c *Companion) *InLineBuilder {
+	if b.creative == nil || b.creative.CompanionAds == nil {
+		return b.fail("Companion called outside of a companion creative")
+	}
+	b.creative.CompanionAds.Companions = append(b.creative.CompanionAds.Companions, c)
+	return b
+}
+
+// Tracking adds a tracking event to the current creative. For companion
+// creatives, the event is added to the last added companion.
+func (b *InLineBuilder) Tracking(event TrackingEvent, uri string) *InLineBuilder {
+	t := &Tracking{Event: string(event), URI: uri}
+	switch c := b.creative; {
+	case c == nil:
+		return b.fail("Tracking called before adding a creative")
+	case c.Linear != nil:
+		c.Linear.TrackingEvents = append(c.Linear.TrackingEvents, t)
+	case c.NonLinearAds != nil:
+		c.NonLinearAds.TrackingEvents = append(c.NonLinearAds.TrackingEvents, t)
+	case len(c.CompanionAds.Companions) == 0:
+		return b.fail("Tracking called before adding a companion")
+	default:
+		comp := c.CompanionAds.Companions[len(c.CompanionAds.Companions)-1]
+		comp.TrackingEvents = append(comp.TrackingEvents, t)
+	}
+	return b
+}
+
+// ProgressTracking adds a progress tracking event at the given offset to the
+// current linear creative.
+func (b *InLineBuilder) ProgressTracking(offset Offset, uri string) *InLineBuilder {
+	if b.creative == nil || b.creative.Linear == nil {
+		return b.fail("ProgressTracking called outside of a linear creative")
+	}
+	b.creative.Linear.TrackingEvents = append(b.creative.Linear.TrackingEvents, &Tracking{Event: string(EventProgress), Offset: &offset, URI: uri})
+	return b
+}
+
+// Build returns the built document. An error is returned if the builder was
+// misused or if the document does not pass validation.
+func (b *InLineBuilder) Build() (*VAST, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	for _, v := range b.vast.Validate("") {
+		if v.Severity == SeverityError {
+			return nil, fmt.Errorf("vast: invalid document: %s", v)
+		}
+	}
+	return b.vast, nil
+}
@@ -0,0 +1,113 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderInlineLinear(t *testing.T) {
+	v, err := NewInLine("601364", "Acudeo Compatible", "VAST 2.0 Instream Test 1").
+		Version("2.0").
+		AdSystemVersion("1.0").
+		Description("VAST 2.0 Instream Test 1").
+		Error("http://myErrorURL/error").
+		Error("http://myErrorURL/error2").
+		Impression("http://myTrackingURL/impression").
+		ImpressionWithID("foo", "http://myTrackingURL/impression2").
+		LinearCreative(30*time.Second).
+		AdID("601364").
+		Tracking("creativeView", "http://myTrackingURL/creativeView").
+		Tracking("start", "http://myTrackingURL/start").
+		Tracking("midpoint", "http://myTrackingURL/midpoint").
+		Tracking("firstQuartile", "http://myTrackingURL/firstQuartile").
+		Tracking("thirdQuartile", "http://myTrackingURL/thirdQuartile").
+		Tracking("complete", "http://myTrackingURL/complete").
+		ClickThrough("http://www.tremormedia.com").
+		ClickTracking("http://myTrackingURL/click").
+		MediaFile(&MediaFile{
+			Delivery: "progressive", Type: "video/x-flv", Bitrate: 500, Width: 400, Height: 300,
+			Scalable: true, MaintainAspectRatio: true,
+			URI: "http://cdnp.tremormedia.com/video/acudeo/Carrot_400x300_500kb.flv",
+		}).
+		CompanionCreative("all").
+		AdID("601364-Companion").
+		Companion(&Companion{
+			Width: 300, Height: 250,
+			StaticResource:        &StaticResource{CreativeType: "image/jpeg", URI: "http://demo.tremormedia.com/proddev/vast/Blistex1.jpg"},
+			CompanionClickThrough: "http://www.tremormedia.com",
+		}).
+		Tracking("creativeView", "http://myTrackingURL/firstCompanionCreativeView").
+		Companion(&Companion{
+			Width: 728, Height: 90,
+			StaticResource:        &StaticResource{CreativeType: "image/jpeg", URI: "http://demo.tremormedia.com/proddev/vast/728x90_banner1.jpg"},
+			CompanionClickThrough: "http://www.tremormedia.com",
+		}).
+		Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	b, err := xml.Marshal(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var built VAST
+	if !assert.NoError(t, xml.Unmarshal(b, &built)) {
+		return
+	}
+	expected, err := loadFixture("testdata/vast_inline_linear.xml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, &built)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	_, err := NewInLine("1", "sys", "title").Impression("http://t").MediaFile(&MediaFile{}).Build()
+	assert.EqualError(t, err, "vast: MediaFile called outside of a linear creative")
+
+	_, err = NewInLine("1", "sys", "title").Impression("http://t").CompanionCreative("").Tracking("creativeView", "http://t").Build()
+	assert.EqualError(t, err, "vast: Tracking called before adding a companion")
+
+	_, err = NewInLine("1", "sys", "title").LinearCreative(time.Second).Build()
+	assert.EqualError(t, err, "vast: invalid document: error: Ads[0].InLine: missing Impression (VAST 3.0 InLine/Impression)")
+}
+
+func TestBuilderVAST4(t *testing.T) {
+	b := NewInLine("1", "sys", "title").
+		Version("4.1").
+		Impression("http://t/imp").
+		LinearCreative(10*time.Second).
+		MediaFile(&MediaFile{Delivery: "progressive", Type: "video/mp4", Width: 640, Height: 360, URI: "http://t/video.mp4"}).
+		Tracking("loaded", "http://t/loaded")
+	_, err := b.Build()
+	assert.EqualError(t, err, "vast: invalid document: error: Ads[0].InLine: missing AdServingId (VAST 4.1 InLine/AdServingId)")
+
+	v, err := b.AdServingID("a1b2c3").UniversalAdID("ad-id.org", "CNPA0484000H").Build()
+	if assert.NoError(t, err) {
+		in := v.Ads[0].InLine
+		assert.Equal(t, "a1b2c3", in.AdServingID)
+		assert.Equal(t, []*UniversalAdID{{IDRegistry: "ad-id.org", ID: "CNPA0484000H"}}, in.Creatives[0].UniversalAdIDs)
+	}
+
+	_, err = NewInLine("1", "sys", "title").UniversalAdID("ad-id.org", "x").Build()
+	assert.EqualError(t, err, "vast: UniversalAdID called before adding a creative")
+}
+
+func TestBuilderNonLinear(t *testing.T) {
+	v, err := NewInLine("1", "sys", "title").
+		Impression("http://t/imp").
+		NonLinearCreative().
+		NonLinear(NonLinear{Width: 300, Height: 50, StaticResource: &StaticResource{CreativeType: "image/png", URI: "http://t/img.png"}}).
+		Tracking("expand", "http://t/expand").
+		Build()
+	if assert.NoError(t, err) {
+		nla := v.Ads[0].InLine.Creatives[0].NonLinearAds
+		assert.Len(t, nla.NonLinears, 1)
+		if assert.Len(t, nla.TrackingEvents, 1) {
+			assert.Equal(t, "expand", nla.TrackingEvents[0].Event)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package vast
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCompanionRequired is returned by SelectCompanions when the companions
+// can't be displayed as required by CompanionAds.Required. The ad should then
+// not be played and the ErrorCompanionRequired error code reported.
+var ErrCompanionRequired = errors.New("vast: required companions cannot be displayed")
+
+// Kinds of companion resources, as used in Slot.Resources
+const (
+	ResourceStatic = "static"
+	ResourceIFrame = "iframe"
+	ResourceHTML   = "html"
+)
+
+// Slot is a placement of the page able to display a companion ad
+type Slot struct {
+	// The identifier of the slot, matched against Companion.AdSlotID when
+	// both are set
+	ID string
+	// The size of the slot in pixels, matched exactly against the companion
+	// size when not zero
+	Width  int
+	Height int
+	// The kinds of resources the slot can display among ResourceStatic,
+	// ResourceIFrame and ResourceHTML. All kinds are accepted if empty.
+	Resources []string
+	// The MIME types of the static resources the slot can display, which may
+	// contain "image/*" style wildcards. All types are accepted if empty.
+	StaticTypes []string
+}
+
+// CompanionAssignment is a companion chosen to be displayed in a slot
+type CompanionAssignment struct {
+	Slot      Slot
+	Companion *Companion
+}
+
+// SelectCompanions assigns the companions of ca to the slots of the page. Each
+// slot receives at most one companion and each companion is displayed at most
+// once. A companion matches a slot if their ad slot ids and sizes match and
+// the slot can display one of the companion resources. Companions targeting
+// a slot by id are preferred.
+//
+// ErrCompanionRequired is returned when all the companions are required but
+// some can't be displayed, or when any companion is required but none can be
+// displayed. Assignments are returned in slot order.
+func SelectCompanions(ca *CompanionAds, slots []Slot) ([]CompanionAssignment, error) {
+	if ca == nil {
+		return nil, nil
+	}
+	// Companions matching each slot, exact slot id matches first
+	candidates := make([][]int, len(slots))
+	for i, s := range slots {
+		var others []int
+		for j, c := range ca.Companions {
+			if !companionFits(c, s) {
+				continue
+			}
+			if s.ID != "" && c.AdSlotID == s.ID {
+				candidates[i] = append(candidates[i], j)
+			} else {
+				others = append(others, j)
+			}
+		}
+		candidates[i] = append(candidates[i], others...)
+	}
+
+	// Maximum bipartite matching using augmenting paths
+	slotOf := make([]int, len(ca.Companions))
+	for j := range slotOf {
+		slotOf[j] = -1
+	}
+	var assign func(i int, visited []bool) bool
+	assign = func(i int, visited []bool) bool {
+		for _, j := range candidates[i] {
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			if slotOf[j] < 0 || assign(slotOf[j], visited) {
+				slotOf[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	n := 0
+	for i := range slots {
+		if assign(i, make([]bool, len(ca.Companions))) {
+			n++
+		}
+	}
+
+	switch ca.Required {
+	case "all":
+		if n < len(ca.Companions) {
+			return nil, fmt.Errorf("%w: %d of %d companions can be displayed", ErrCompanionRequired, n, len(ca.Companions))
+		}
+	case "any":
+		if n == 0 && len(ca.Companions) > 0 {
+			return nil, fmt.Errorf("%w: no companion can be displayed", ErrCompanionRequired)
+		}
+	}
+
+	companionOf := make([]int, len(slots))
+	for i := range companionOf {
+		companionOf[i] = -1
+	}
+	for j, i := range slotOf {
+		if i >= 0 {
+			companionOf[i] = j
+		}
+	}
+	var assignments []CompanionAssignment
+	for i, j := range companionOf {
+		if j >= 0 {
+			assignments = append(assignments, CompanionAssignment{Slot: slots[i], Companion: ca.Companions[j]})
+		}
+	}
+	return assignments, nil
+}
+
+// companionFits tells if the companion can be displayed in the slot
+func companionFits(c *Companion, s Slot) bool {
+	if c.AdSlotID != "" && s.ID != "" && c.AdSlotID != s.ID {
+		return false
+	}
+	if (s.Width > 0 && c.Width != s.Width) || (s.Height > 0 && c.Height != s.Height) {
+		return false
+	}
+	if c.StaticResource != nil && matchAny(s.Resources, ResourceStatic) && matchMIMEType(s.StaticTypes, c.StaticResource.CreativeType) {
+		return true
+	}
+	if c.IFrameResource != "" && matchAny(s.Resources, ResourceIFrame) {
+		return true
+	}
+	return c.HTMLResource != nil && matchAny(s.Resources, ResourceHTML)
+}
@@ -0,0 +1,81 @@
+package vast
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectCompanions(t *testing.T) {
+	v, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ca := v.Ads[0].InLine.Creatives[1].CompanionAds
+	assert.Equal(t, "all", ca.Required)
+
+	slots := []Slot{
+		{ID: "banner", Width: 728, Height: 90},
+		{ID: "rectangle", Width: 300, Height: 250, StaticTypes: []string{"image/*"}},
+	}
+	as, err := SelectCompanions(ca, slots)
+	if assert.NoError(t, err) && assert.Len(t, as, 2) {
+		assert.Equal(t, "banner", as[0].Slot.ID)
+		assert.Equal(t, 728, as[0].Companion.Width)
+		assert.Equal(t, "rectangle", as[1].Slot.ID)
+		assert.Equal(t, 300, as[1].Companion.Width)
+	}
+
+	_, err = SelectCompanions(ca, slots[:1])
+	assert.EqualError(t, err, "vast: required companions cannot be displayed: 1 of 2 companions can be displayed")
+	assert.True(t, errors.Is(err, ErrCompanionRequired))
+	_, err = SelectCompanions(ca, []Slot{{Width: 300, Height: 250, StaticTypes: []string{"image/png"}}, slots[0]})
+	assert.Error(t, err)
+
+	ca.Required = "any"
+	as, err = SelectCompanions(ca, slots[:1])
+	if assert.NoError(t, err) {
+		assert.Len(t, as, 1)
+	}
+	_, err = SelectCompanions(ca, []Slot{{Resources: []string{ResourceHTML}}})
+	assert.EqualError(t, err, "vast: required companions cannot be displayed: no companion can be displayed")
+	assert.True(t, errors.Is(err, ErrCompanionRequired))
+
+	ca.Required = "none"
+	as, err = SelectCompanions(ca, nil)
+	assert.NoError(t, err)
+	assert.Len(t, as, 0)
+}
+
+func TestSelectCompanionsMatching(t *testing.T) {
+	ca := &CompanionAds{Required: "all", Companions: []*Companion{
+		{ID: "html", Width: 300, Height: 250, HTMLResource: &HTMLResource{}},
+		{ID: "frame", Width: 300, Height: 250, IFrameResource: "http://example.com/frame"},
+	}}
+	// The first slot would take the html companion if assigned greedily,
+	// leaving no companion for the second one
+	slots := []Slot{
+		{ID: "top", Width: 300, Height: 250},
+		{ID: "side", Width: 300, Height: 250, Resources: []string{ResourceHTML}},
+	}
+	as, err := SelectCompanions(ca, slots)
+	if assert.NoError(t, err) && assert.Len(t, as, 2) {
+		assert.Equal(t, "frame", as[0].Companion.ID)
+		assert.Equal(t, "html", as[1].Companion.ID)
+	}
+
+	// Companions targeting a slot are only displayed in that slot
+	ca.Companions[1].AdSlotID = "side"
+	_, err = SelectCompanions(ca, slots)
+	assert.Error(t, err)
+	ca.Companions[1].AdSlotID = "top"
+	as, err = SelectCompanions(ca, slots)
+	if assert.NoError(t, err) && assert.Len(t, as, 2) {
+		assert.Equal(t, "frame", as[0].Companion.ID)
+	}
+
+	as, err = SelectCompanions(nil, slots)
+	assert.NoError(t, err)
+	assert.Nil(t, as)
+}
@@ -0,0 +1,46 @@
+package vast
+
+import "encoding/xml"
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (ad *Ad) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type adAlias Ad
+	if err := d.DecodeElement((*adAlias)(ad), &start); err != nil {
+		return err
+	}
+	for _, a := range start.Attr {
+		if a.Name.Local == "sequence" && ad.Sequence == 0 {
+			ad.zeroSequence = true
+		}
+	}
+	return nil
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+//
+// VAST 4 renamed the AdID attribute of creatives to adId, both are accepted.
+func (c *Creative) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type creative Creative
+	start.Attr = renameAdIDAttr(start.Attr)
+	return d.DecodeElement((*creative)(c), &start)
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+//
+// VAST 4 renamed the AdID attribute of creatives to adId, both are accepted.
+func (c *CreativeWrapper) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type creativeWrapper CreativeWrapper
+	start.Attr = renameAdIDAttr(start.Attr)
+	return d.DecodeElement((*creativeWrapper)(c), &start)
+}
+
+func renameAdIDAttr(attrs []xml.Attr) []xml.Attr {
+	renamed := make([]xml.Attr, len(attrs))
+	for i, a := range attrs {
+		if a.Name.Local == "adId" {
+			a.Name.Local = "AdID"
+		}
+		renamed[i] = a
+	}
+	return renamed
+}
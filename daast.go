@@ -0,0 +1,101 @@
+package vast
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// AudioMIMETypes lists the common MIME types of audio media files. It can be
+// used as MediaCriteria.Types to select audio renditions.
+var AudioMIMETypes = []string{
+	"audio/mpeg",
+	"audio/mp4",
+	"audio/aac",
+	"audio/ogg",
+	"audio/wav",
+	"audio/x-wav",
+	"audio/webm",
+}
+
+// IsAudio tells if the media file is an audio file, based on its MIME type.
+func (mf *MediaFile) IsAudio() bool {
+	t := strings.ToLower(strings.TrimSpace(mf.Type))
+	return strings.HasPrefix(t, "audio/")
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+//
+// The DAASTAdTagURI of DAAST wrappers is stored in VASTAdTagURI.
+func (w *Wrapper) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type wrapper Wrapper
+	var dw struct {
+		wrapper
+		DAASTAdTagURI string
+	}
+	if err := d.DecodeElement(&dw, &start); err != nil {
+		return err
+	}
+	*w = Wrapper(dw.wrapper)
+	if w.VASTAdTagURI == "" {
+		w.VASTAdTagURI = dw.DAASTAdTagURI
+	}
+	return nil
+}
+
+// DecodeDAAST reads an IAB DAAST 1.0 (Digital Audio Ad Serving Template)
+// document from r into the VAST model.
+//
+// DAAST shares most of its structure with VAST 3.0. The DAASTAdTagURI of
+// wrappers is stored in Wrapper.VASTAdTagURI, the AdType of ads is set to
+// "audio" and the Version is kept as found in the document (e.g. "1.0"), which
+// Validate checks against the VAST 3.0 rules.
+func DecodeDAAST(r io.Reader) (*VAST, error) {
+	v, _, err := decode(r)
+	if err != nil {
+		return nil, err
+	}
+	setAudio(v)
+	return v, nil
+}
+
+// decodeDocument reads a VAST or DAAST document from r. DAAST documents are
+// decoded as by DecodeDAAST.
+func decodeDocument(r io.Reader) (*VAST, error) {
+	v, daast, err := decode(r)
+	if err != nil {
+		return nil, err
+	}
+	if daast {
+		setAudio(v)
+	}
+	return v, nil
+}
+
+// decode decodes a VAST or DAAST document and tells if it was a DAAST one
+func decode(r io.Reader) (*VAST, bool, error) {
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, false, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			// The VAST model accepts any root element name
+			var v VAST
+			if err := d.DecodeElement(&v, &start); err != nil {
+				return nil, false, err
+			}
+			return &v, start.Name.Local == "DAAST", nil
+		}
+	}
+}
+
+// setAudio sets the AdType of the ads of v to "audio" when not set
+func setAudio(v *VAST) {
+	for _, ad := range v.Ads {
+		if ad.AdType == "" {
+			ad.AdType = "audio"
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package vast
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loadDAASTFixture(path string) (*VAST, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeDAAST(f)
+}
+
+func TestDAASTInLine(t *testing.T) {
+	v, err := loadDAASTFixture("testdata/daast_inline_audio.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "1.0", v.Version)
+	if assert.Len(t, v.Ads, 1) {
+		ad := v.Ads[0]
+		assert.Equal(t, "audio", ad.AdType)
+		if assert.NotNil(t, ad.InLine) && assert.Len(t, ad.InLine.Creatives, 2) {
+			assert.Equal(t, "ACME Motors", ad.InLine.Advertiser)
+			if assert.Len(t, ad.InLine.Categories, 1) {
+				assert.Equal(t, "IAB2", ad.InLine.Categories[0].Code)
+			}
+			linear := ad.InLine.Creatives[0].Linear
+			if assert.NotNil(t, linear) && assert.Len(t, linear.MediaFiles, 3) {
+				assert.True(t, linear.MediaFiles[0].IsAudio())
+				assert.Equal(t, "http://cdn.example.com/spot-128.mp3", linear.MediaFiles[0].URI)
+			}
+			companions := ad.InLine.Creatives[1].CompanionAds
+			if assert.NotNil(t, companions) && assert.Len(t, companions.Companions, 1) {
+				assert.Equal(t, "image/png", companions.Companions[0].StaticResource.CreativeType)
+			}
+		}
+	}
+	assert.Len(t, v.Validate(""), 0)
+
+	v.Ads[0].InLine.AdTitle = ""
+	if violations := v.Validate(""); assert.Len(t, violations, 1) {
+		assert.Equal(t, "DAAST 1.0 InLine/AdTitle", violations[0].Ref)
+	}
+}
+
+func TestDAASTWrapper(t *testing.T) {
+	v, err := loadDAASTFixture("testdata/daast_wrapper.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, v.Ads, 1) && assert.NotNil(t, v.Ads[0].Wrapper) {
+		assert.Equal(t, "audio", v.Ads[0].AdType)
+		assert.Equal(t, "http://example.com/daast_inline_audio.xml", v.Ads[0].Wrapper.VASTAdTagURI)
+	}
+}
+
+func TestDAASTExtensions(t *testing.T) {
+	// Raw XML, such as extensions, is kept when decoding DAAST
+	v, err := DecodeDAAST(strings.NewReader(`<DAAST version="1.0"><Ad id="1"><Wrapper>
+	<DAASTAdTagURI>http://example.com/daast.xml</DAASTAdTagURI>
+	<Extensions><Extension type="acme"><Tracker>http://t/x</Tracker></Extension></Extensions>
+</Wrapper></Ad></DAAST>`))
+	if assert.NoError(t, err) && assert.Len(t, v.Ads, 1) {
+		w := v.Ads[0].Wrapper
+		assert.Equal(t, "http://example.com/daast.xml", w.VASTAdTagURI)
+		if assert.NotNil(t, w.Extensions) && assert.Len(t, w.Extensions.Extensions, 1) {
+			assert.Equal(t, "<Tracker>http://t/x</Tracker>", string(w.Extensions.Extensions[0].Data))
+		}
+	}
+
+	f, err := os.Open("testdata/vast_extensions.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	v, err = decodeDocument(f)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", v.Ads[0].AdType)
+		assert.Len(t, v.Ads[0].Verifications(), 1)
+	}
+}
+
+func TestSelectAudioMediaFile(t *testing.T) {
+	v, err := loadDAASTFixture("testdata/daast_inline_audio.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	files := append(v.Ads[0].InLine.Creatives[0].Linear.MediaFiles,
+		&MediaFile{Delivery: "progressive", Type: "video/mp4", Width: 640, Height: 360, Bitrate: 800})
+	ranked := SelectMediaFile(files, MediaCriteria{Types: AudioMIMETypes, Bandwidth: 100})
+	if assert.Len(t, ranked, 3) {
+		assert.Equal(t, "http://cdn.example.com/spot-96.aac", ranked[0].MediaFile.URI)
+		assert.Equal(t, "http://cdn.example.com/spot-64.mp3", ranked[1].MediaFile.URI)
+	}
+	ranked = SelectMediaFile(files, MediaCriteria{Types: []string{"audio/*"}})
+	assert.Len(t, ranked, 3)
+}
+
+func TestAdType(t *testing.T) {
+	v := &VAST{Version: "4.1", Ads: []*Ad{{AdType: "radio", Wrapper: &Wrapper{
+		AdSystem:     &AdSystem{Name: "sys"},
+		VASTAdTagURI: "http://t",
+		Impressions:  []*Impression{{URI: "http://t"}},
+	}}}}
+	if violations := v.Validate(""); assert.Len(t, violations, 1) {
+		assert.Equal(t, `invalid adType "radio"`, violations[0].Message)
+	}
+}
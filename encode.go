@@ -0,0 +1,236 @@
+package vast
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// cdataElements lists the elements whose character data is written in a CDATA
+// section by Marshal and Encode: URIs, HTML and ad parameters
+var cdataElements = map[string]bool{
+	"AdParameters":            true,
+	"ClickThrough":            true,
+	"ClickTracking":           true,
+	"ClosedCaptionFile":       true,
+	"CompanionClickThrough":   true,
+	"CompanionClickTracking":  true,
+	"CustomClick":             true,
+	"Error":                   true,
+	"ExecutableResource":      true,
+	"HTMLResource":            true,
+	"IconClickThrough":        true,
+	"IconClickTracking":       true,
+	"IconViewTracking":        true,
+	"IFrameResource":          true,
+	"Impression":              true,
+	"InteractiveCreativeFile": true,
+	"JavaScriptResource":      true,
+	"MediaFile":               true,
+	"Mezzanine":               true,
+	"NonLinearClickThrough":   true,
+	"NonLinearClickTracking":  true,
+	"NotViewable":             true,
+	"StaticResource":          true,
+	"Survey":                  true,
+	"Tracking":                true,
+	"VASTAdTagURI":            true,
+	"VerificationParameters":  true,
+	"Viewable":                true,
+	"ViewUndetermined":        true,
+}
+
+// emptyContainers lists the optional container elements omitted by Marshal and
+// Encode when empty. xml.Marshal writes them even when they have no child.
+var emptyContainers = map[string]bool{
+	"AdVerifications":    true,
+	"ClosedCaptionFiles": true,
+	"TrackingEvents":     true,
+}
+
+// verbatimElements lists the elements whose content is copied as is by
+// Marshal and Encode
+var verbatimElements = map[string]bool{
+	"Extension":         true,
+	"CreativeExtension": true,
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;",
+		"\r", "&#xD;", "\n", "&#xA;", "\t", "&#x9;")
+)
+
+// Marshal returns the XML encoding of v, preceded by a XML declaration.
+//
+// Unlike xml.Marshal, URIs, HTML resources and ad parameters are written in
+// CDATA sections so characters like & in tracking URLs are not escaped. The
+// AdID attribute of creatives is written as adId in VAST 4 documents.
+func Marshal(v *VAST) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes the XML encoding of v to w as described in Marshal.
+func Encode(w io.Writer, v *VAST) error {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	if err := writeCDATA(bw, b); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeCDATA copies the XML document b to w, turning the character data of
+// cdataElements into CDATA sections and dropping empty emptyContainers. Raw
+// tokens are used so namespace prefixes of extensions are preserved.
+func writeCDATA(w *bufio.Writer, b []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	var stack []string
+	// An empty container start element is only written once we know it has content
+	var pending *xml.StartElement
+	vast4 := false
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if pending != nil {
+			if _, ok := tok.(xml.EndElement); ok {
+				pending = nil
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			writeStartElement(w, *pending, false)
+			pending = nil
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			switch {
+			case len(stack) == 1:
+				vast4 = compareVersions(attrValue(t, "version"), "4.0") >= 0
+			case vast4 && t.Name.Local == "Creative":
+				t = renameAttr(t, "AdID", "adId")
+			}
+			if emptyContainers[t.Name.Local] && len(t.Attr) == 0 {
+				t = t.Copy()
+				pending = &t
+				continue
+			}
+			if verbatimElements[t.Name.Local] {
+				stack = stack[:len(stack)-1]
+				if err := writeVerbatim(w, d, b, t); err != nil {
+					return err
+				}
+				continue
+			}
+			writeStartElement(w, t, false)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			w.WriteString("</" + qualifiedName(t.Name) + ">")
+		case xml.CharData:
+			if len(stack) > 0 && cdataElements[stack[len(stack)-1]] && len(bytes.TrimSpace(t)) > 0 {
+				w.WriteString("<![CDATA[")
+				w.WriteString(strings.Replace(string(t), "]]>", "]]]]><![CDATA[>", -1))
+				w.WriteString("]]>")
+			} else {
+				textEscaper.WriteString(w, string(t))
+			}
+		case xml.Comment:
+			w.WriteString("<!--")
+			w.Write(t)
+			w.WriteString("-->")
+		case xml.ProcInst:
+			w.WriteString("<?" + t.Target + " ")
+			w.Write(t.Inst)
+			w.WriteString("?>")
+		case xml.Directive:
+			w.WriteString("<!")
+			w.Write(t)
+			w.WriteString(">")
+		}
+	}
+}
+
+// writeVerbatim writes the element started by t, the last token read from d,
+// copying its content as is from the XML document b.
+func writeVerbatim(w *bufio.Writer, d *xml.Decoder, b []byte, t xml.StartElement) error {
+	start := d.InputOffset()
+	for depth := 1; depth > 0; {
+		tok, err := d.RawToken()
+		if err != nil {
+			return err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	// xml.Marshal writes end tags without extra spaces
+	end := d.InputOffset() - int64(len("</"+qualifiedName(t.Name)+">"))
+	if start == end {
+		// Keep empty elements self-closing so their content decodes as nil
+		writeStartElement(w, t, true)
+		return nil
+	}
+	writeStartElement(w, t, false)
+	w.Write(b[start:end])
+	w.WriteString("</" + qualifiedName(t.Name) + ">")
+	return nil
+}
+
+func writeStartElement(w *bufio.Writer, t xml.StartElement, selfClosing bool) {
+	w.WriteString("<" + qualifiedName(t.Name))
+	for _, a := range t.Attr {
+		w.WriteString(" " + qualifiedName(a.Name) + `="`)
+		attrEscaper.WriteString(w, a.Value)
+		w.WriteString(`"`)
+	}
+	if selfClosing {
+		w.WriteString("/>")
+		return
+	}
+	w.WriteString(">")
+}
+
+func attrValue(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// renameAttr returns a copy of t with the attribute from renamed to
+func renameAttr(t xml.StartElement, from, to string) xml.StartElement {
+	t = t.Copy()
+	for i, a := range t.Attr {
+		if a.Name.Space == "" && a.Name.Local == from {
+			t.Attr[i].Name.Local = to
+		}
+	}
+	return t
+}
+
+func qualifiedName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
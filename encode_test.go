@@ -0,0 +1,133 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalCDATA(t *testing.T) {
+	v := &VAST{
+		Version: "3.0",
+		Errors:  []string{"http://t/noad?a=1&b=[ERRORCODE]"},
+		Ads: []*Ad{{InLine: &InLine{
+			AdTitle:     "Fish & Chips",
+			Impressions: []*Impression{{ID: `a"b`, URI: "http://t/imp?a=1&b=2"}},
+			Creatives: []*Creative{{CompanionAds: &CompanionAds{Companions: []*Companion{{
+				HTMLResource: &HTMLResource{HTML: []byte(`<div>]]></div>`)},
+			}}}}},
+		}}},
+	}
+	b, err := Marshal(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	s := string(b)
+	assert.True(t, strings.HasPrefix(s, `<?xml version="1.0" encoding="UTF-8"?>`+"\n<VAST"))
+	assert.Contains(t, s, `<Error><![CDATA[http://t/noad?a=1&b=[ERRORCODE]]]></Error>`)
+	assert.Contains(t, s, `<AdTitle>Fish &amp; Chips</AdTitle>`)
+	assert.Contains(t, s, `<Impression id="a&quot;b"><![CDATA[http://t/imp?a=1&b=2]]></Impression>`)
+	assert.Contains(t, s, `<HTMLResource><![CDATA[<div>]]]]><![CDATA[></div>]]></HTMLResource>`)
+	assert.NotContains(t, s, `<TrackingEvents>`)
+	assert.NotContains(t, s, `<AdVerifications>`)
+
+	var v2 VAST
+	if assert.NoError(t, xml.Unmarshal(b, &v2)) {
+		assert.Equal(t, v, &v2)
+	}
+}
+
+func TestMarshalAdIDAttr(t *testing.T) {
+	for version, attr := range map[string]string{"3.0": `AdID="1"`, "4.1": `adId="1"`} {
+		v := &VAST{Version: version, Ads: []*Ad{{InLine: &InLine{Creatives: []*Creative{{AdID: "1"}}}}}}
+		b, err := Marshal(v)
+		if assert.NoError(t, err) {
+			assert.Contains(t, string(b), `<Creative `+attr+`>`, version)
+			var v2 VAST
+			if assert.NoError(t, xml.Unmarshal(b, &v2)) {
+				assert.Equal(t, v, &v2)
+			}
+		}
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, path := range files {
+		root, err := fixtureRoot(path)
+		if !assert.NoError(t, err, path) {
+			continue
+		}
+		switch root {
+		case "VAST", "DAAST":
+			decode := func(b []byte) (*VAST, error) {
+				var v VAST
+				err := xml.Unmarshal(b, &v)
+				return &v, err
+			}
+			if root == "DAAST" {
+				decode = func(b []byte) (*VAST, error) {
+					return DecodeDAAST(bytes.NewReader(b))
+				}
+			}
+			b, err := ioutil.ReadFile(path)
+			if !assert.NoError(t, err, path) {
+				continue
+			}
+			v, err := decode(b)
+			if !assert.NoError(t, err, path) {
+				continue
+			}
+			if b, err = Marshal(v); !assert.NoError(t, err, path) {
+				continue
+			}
+			v2, err := decode(b)
+			if assert.NoError(t, err, path) {
+				assert.Equal(t, v, v2, path)
+			}
+		case "VMAP":
+			v, err := loadVMAPFixture(path)
+			if !assert.NoError(t, err, path) {
+				continue
+			}
+			b, err := xml.Marshal(v)
+			if !assert.NoError(t, err, path) {
+				continue
+			}
+			var v2 VMAP
+			if assert.NoError(t, xml.Unmarshal(b, &v2), path) {
+				assert.Equal(t, v, &v2, path)
+			}
+		default:
+			t.Errorf("%s: unexpected root element %s", path, root)
+		}
+	}
+}
+
+// fixtureRoot returns the name of the root element of the XML file at path
+func fixtureRoot(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	d := xml.NewDecoder(f)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		if t, ok := tok.(xml.StartElement); ok {
+			return t.Name.Local, nil
+		}
+	}
+}
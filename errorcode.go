@@ -0,0 +1,143 @@
+package vast
+
+import "strconv"
+
+// ErrorCode is a VAST error code, as substituted to the [ERRORCODE] macro of
+// error URIs.
+type ErrorCode int
+
+// Error codes defined by the VAST spec.
+const (
+	ErrorXMLParsing                 ErrorCode = 100
+	ErrorSchemaValidation           ErrorCode = 101
+	ErrorVersionNotSupported        ErrorCode = 102
+	ErrorTrafficking                ErrorCode = 200
+	ErrorUnexpectedLinearity        ErrorCode = 201
+	ErrorUnexpectedDuration         ErrorCode = 202
+	ErrorUnexpectedSize             ErrorCode = 203
+	ErrorAdCategoryRequired         ErrorCode = 204
+	ErrorBlockedAdCategory          ErrorCode = 205
+	ErrorWrapper                    ErrorCode = 300
+	ErrorWrapperTimeout             ErrorCode = 301
+	ErrorWrapperLimit               ErrorCode = 302
+	ErrorWrapperNoAd                ErrorCode = 303
+	ErrorInLineDisplayTimeout       ErrorCode = 304
+	ErrorLinear                     ErrorCode = 400
+	ErrorMediaFileNotFound          ErrorCode = 401
+	ErrorMediaFileTimeout           ErrorCode = 402
+	ErrorMediaFileNotSupported      ErrorCode = 403
+	ErrorMediaFileDisplay           ErrorCode = 405
+	ErrorMezzanineRequired          ErrorCode = 406
+	ErrorMezzanineDownloadNotDone   ErrorCode = 407
+	ErrorConditionalAdRejected      ErrorCode = 408
+	ErrorInteractiveNotExecuted     ErrorCode = 409
+	ErrorVerificationNotExecuted    ErrorCode = 410
+	ErrorMezzanineNotUsable         ErrorCode = 411
+	ErrorNonLinear                  ErrorCode = 500
+	ErrorNonLinearDimensions        ErrorCode = 501
+	ErrorNonLinearFetch             ErrorCode = 502
+	ErrorNonLinearNotSupported      ErrorCode = 503
+	ErrorCompanion                  ErrorCode = 600
+	ErrorCompanionDimensions        ErrorCode = 601
+	ErrorCompanionRequired          ErrorCode = 602
+	ErrorCompanionFetch             ErrorCode = 603
+	ErrorCompanionNotSupported      ErrorCode = 604
+	ErrorUndefined                  ErrorCode = 900
+	ErrorVPAID                      ErrorCode = 901
+	ErrorInteractiveCreativeGeneral ErrorCode = 902
+)
+
+var errorCodeDescriptions = map[ErrorCode]string{
+	ErrorXMLParsing:                 "XML parsing error",
+	ErrorSchemaValidation:           "VAST schema validation error",
+	ErrorVersionNotSupported:        "VAST version of response not supported",
+	ErrorTrafficking:                "Trafficking error. Video player received an ad type that it was not expecting and/or cannot display",
+	ErrorUnexpectedLinearity:        "Video player expecting different linearity",
+	ErrorUnexpectedDuration:         "Video player expecting different duration",
+	ErrorUnexpectedSize:             "Video player expecting different size",
+	ErrorAdCategoryRequired:         "Ad category was required but not provided",
+	ErrorBlockedAdCategory:          "InLine Category violates Wrapper BlockedAdCategories",
+	ErrorWrapper:                    "General Wrapper error",
+	ErrorWrapperTimeout:             "Timeout of VAST URI provided in Wrapper element, or of VAST URI provided in a subsequent Wrapper element",
+	ErrorWrapperLimit:               "Wrapper limit reached, as defined by the video player",
+	ErrorWrapperNoAd:                "No VAST response after one or more Wrappers",
+	ErrorInLineDisplayTimeout:       "InLine response returned ad unit that failed to result in ad display within defined time limit",
+	ErrorLinear:                     "General Linear error. Video player is unable to display the Linear Ad",
+	ErrorMediaFileNotFound:          "File not found. Unable to find Linear/MediaFile from URI",
+	ErrorMediaFileTimeout:           "Timeout of MediaFile URI",
+	ErrorMediaFileNotSupported:      "Couldn't find MediaFile that is supported by this video player, based on the attributes of the MediaFile element",
+	ErrorMediaFileDisplay:           "Problem displaying MediaFile",
+	ErrorMezzanineRequired:          "Mezzanine was required but not provided",
+	ErrorMezzanineDownloadNotDone:   "Mezzanine is in the process of being downloaded for the first time",
+	ErrorConditionalAdRejected:      "Conditional ad rejected",
+	ErrorInteractiveNotExecuted:     "Interactive unit in the InteractiveCreativeFile node was not executed",
+	ErrorVerificationNotExecuted:    "Verification unit in the Verification node was not executed",
+	ErrorMezzanineNotUsable:         "Mezzanine was provided as required, but file is not utilizable",
+	ErrorNonLinear:                  "General NonLinearAds error",
+	ErrorNonLinearDimensions:        "Unable to display NonLinear Ad because creative dimensions do not align with creative display area",
+	ErrorNonLinearFetch:             "Unable to fetch NonLinearAds/NonLinear resource",
+	ErrorNonLinearNotSupported:      "Couldn't find NonLinear resource with supported type",
+	ErrorCompanion:                  "General CompanionAds error",
+	ErrorCompanionDimensions:        "Unable to display Companion because creative dimensions do not fit within Companion display area",
+	ErrorCompanionRequired:          "Unable to display required Companion",
+	ErrorCompanionFetch:             "Unable to fetch CompanionAds/Companion resource",
+	ErrorCompanionNotSupported:      "Couldn't find Companion resource with supported type",
+	ErrorUndefined:                  "Undefined Error",
+	ErrorVPAID:                      "General VPAID error",
+	ErrorInteractiveCreativeGeneral: "General InteractiveCreativeFile error code",
+}
+
+// String returns the description of the error code from the VAST spec.
+func (c ErrorCode) String() string {
+	if d, found := errorCodeDescriptions[c]; found {
+		return d
+	}
+	return "Unknown error code " + strconv.Itoa(int(c))
+}
+
+// ErrorURIs returns the error URIs of all the ads of the document with the
+// [ERRORCODE] macro set to code. The error URIs of the document itself, meant
+// for "no ad" responses, are only included for ErrorWrapperNoAd. Other macros
+// are expanded using m, which may be nil.
+func (v *VAST) ErrorURIs(code ErrorCode, m *Macros) []string {
+	var uris []string
+	if code == ErrorWrapperNoAd {
+		uris = append(uris, v.Errors...)
+	}
+	for _, ad := range v.Ads {
+		uris = append(uris, ad.errors()...)
+	}
+	return expandErrorURIs(uris, code, m)
+}
+
+// ErrorURIs returns the error URIs of the InLine ad and of all the wrappers
+// which led to it with the [ERRORCODE] macro set to code. Other macros are
+// expanded using m, which may be nil.
+func (r *ResolvedAd) ErrorURIs(code ErrorCode, m *Macros) []string {
+	uris := r.Ad.errors()
+	for _, w := range r.Wrappers {
+		uris = append(uris, w.errors()...)
+	}
+	return expandErrorURIs(uris, code, m)
+}
+
+func (ad *Ad) errors() []string {
+	var uris []string
+	if ad.InLine != nil {
+		uris = append(uris, ad.InLine.Errors...)
+	}
+	if ad.Wrapper != nil {
+		uris = append(uris, ad.Wrapper.Errors...)
+	}
+	return uris
+}
+
+func expandErrorURIs(uris []string, code ErrorCode, m *Macros) []string {
+	em := m.clone()
+	em.SetErrorCode(int(code))
+	expanded := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		expanded = append(expanded, em.Expand(uri))
+	}
+	return expanded
+}
@@ -0,0 +1,50 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodeString(t *testing.T) {
+	assert.Equal(t, "Wrapper limit reached, as defined by the video player", ErrorWrapperLimit.String())
+	assert.Equal(t, "InLine Category violates Wrapper BlockedAdCategories", ErrorBlockedAdCategory.String())
+	assert.Equal(t, "Unknown error code 42", ErrorCode(42).String())
+}
+
+func TestErrorURIs(t *testing.T) {
+	v := &VAST{
+		Errors: []string{"http://t/noad?e=[ERRORCODE]"},
+		Ads: []*Ad{
+			{InLine: &InLine{Errors: []string{"http://t/inline?e=[ERRORCODE]&a=[ASSETURI]"}}},
+			{Wrapper: &Wrapper{Errors: []string{" http://t/wrapper?e=%%ERRORCODE%% "}}},
+		},
+	}
+	m := &Macros{}
+	m.SetAssetURI("http://cdn/a.mp4")
+	assert.Equal(t, []string{
+		"http://t/noad?e=303",
+		"http://t/inline?e=303&a=http%3A%2F%2Fcdn%2Fa.mp4",
+		"http://t/wrapper?e=303",
+	}, v.ErrorURIs(ErrorWrapperNoAd, m))
+	_, set := m.Get(MacroErrorCode)
+	assert.False(t, set)
+	assert.Equal(t, []string{"http://t/inline?e=900&a=[ASSETURI]", "http://t/wrapper?e=900"}, v.ErrorURIs(ErrorUndefined, nil))
+}
+
+func TestResolvedAdErrorURIs(t *testing.T) {
+	w, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	r := &ResolvedAd{Ad: v.Ads[0], Wrappers: []*Ad{w.Ads[0]}}
+	assert.Equal(t, []string{
+		"http://myErrorURL/error",
+		"http://myErrorURL/error2",
+		"http://myErrorURL/wrapper/error",
+	}, r.ErrorURIs(ErrorMediaFileNotFound, nil))
+}
@@ -0,0 +1,100 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownExtension is returned by Extension.Decode when no decoder is
+// registered for the extension type.
+var ErrUnknownExtension = errors.New("vast: unknown extension type")
+
+var (
+	extensionsMu sync.RWMutex
+	extensions   = map[string]func() interface{}{}
+)
+
+func init() {
+	RegisterExtension("AdVerifications", func() interface{} { return &AdVerificationsExtension{} })
+	RegisterExtension("waterfall", func() interface{} { return &WaterfallExtension{} })
+	RegisterExtension("SpotX-Count", func() interface{} { return &SpotXCountExtension{} })
+}
+
+// RegisterExtension registers a function returning a new value into which
+// extensions of the given type are decoded by Extension.Decode. The value is
+// decoded with encoding/xml from the whole extension element, so it can access
+// both the attributes and the content of the element.
+//
+// Registering a type again replaces the previous registration.
+func RegisterExtension(typ string, newValue func() interface{}) {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+	extensions[typ] = newValue
+}
+
+// Decode decodes the extension into a new value of the type registered for its
+// type attribute. ErrUnknownExtension is returned if no type is registered.
+func (e *Extension) Decode() (interface{}, error) {
+	extensionsMu.RLock()
+	newValue, found := extensions[e.Type]
+	extensionsMu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExtension, e.Type)
+	}
+	v := newValue()
+	if err := xml.Unmarshal(e.element(), v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// element rebuilds the XML of the whole extension element
+func (e *Extension) element() []byte {
+	name := e.XMLName.Local
+	if name == "" {
+		name = "Extension"
+	}
+	var buf bytes.Buffer
+	buf.WriteString("<" + name)
+	if e.Type != "" {
+		buf.WriteString(` type="`)
+		xml.EscapeText(&buf, []byte(e.Type))
+		buf.WriteString(`"`)
+	}
+	for _, a := range e.Attrs {
+		n := a.Name
+		if n.Space != "xmlns" {
+			// Namespaces are resolved to URLs by the decoder, drop them
+			n.Space = ""
+		}
+		buf.WriteString(" " + qualifiedName(n) + `="`)
+		xml.EscapeText(&buf, []byte(a.Value))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+	buf.Write(e.Data)
+	buf.WriteString("</" + name + ">")
+	return buf.Bytes()
+}
+
+// AdVerificationsExtension is the <Extension type="AdVerifications"> used
+// to carry Open Measurement verifications in VAST 3 and earlier documents.
+type AdVerificationsExtension struct {
+	Verifications []*Verification `xml:"AdVerifications>Verification" json:"verifications,omitempty"`
+}
+
+// WaterfallExtension is the <Extension type="waterfall"> used by Google to
+// tell the position of the ad in an ad waterfall.
+type WaterfallExtension struct {
+	// The 0 based position of the ad in the waterfall
+	FallbackIndex int `xml:"fallback_index,attr" json:"fallback_index"`
+}
+
+// SpotXCountExtension is the <Extension type="SpotX-Count"> used by SpotX to
+// tell the number of ads available for the request.
+type SpotXCountExtension struct {
+	TotalAvailable int `xml:"total_available" json:"total_available"`
+}
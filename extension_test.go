@@ -0,0 +1,100 @@
+package vast
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type geoExtension struct {
+	Country string `xml:"Country"`
+}
+
+func TestExtensions(t *testing.T) {
+	v, err := loadFixture("testdata/vast_extensions.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	in := v.Ads[0].InLine
+	if !assert.NotNil(t, in.Extensions) || !assert.Len(t, in.Extensions.Extensions, 4) {
+		return
+	}
+	exts := in.Extensions.Extensions
+	assert.Equal(t, "Extension", exts[0].XMLName.Local)
+	assert.Equal(t, "waterfall", exts[0].Type)
+	if assert.Len(t, exts[0].Attrs, 1) {
+		assert.Equal(t, "fallback_index", exts[0].Attrs[0].Name.Local)
+	}
+
+	w, err := exts[0].Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, &WaterfallExtension{FallbackIndex: 2}, w)
+	}
+
+	av, err := exts[1].Decode()
+	if assert.NoError(t, err) && assert.IsType(t, &AdVerificationsExtension{}, av) {
+		vers := av.(*AdVerificationsExtension).Verifications
+		if assert.Len(t, vers, 1) {
+			assert.Equal(t, "company.com-omid", vers[0].Vendor)
+			assert.Equal(t, "https://verificationcompany.com/omid.js", vers[0].JavaScriptResources[0].URI)
+			assert.Equal(t, `{"key":"value"}`, vers[0].VerificationParameters)
+		}
+	}
+
+	_, err = exts[2].Decode()
+	assert.EqualError(t, err, `vast: unknown extension type: "geo"`)
+	assert.True(t, errors.Is(err, ErrUnknownExtension))
+	registerTestExtension(t, "geo", func() interface{} { return &geoExtension{} })
+	geo, err := exts[2].Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, &geoExtension{Country: "US"}, geo)
+	}
+
+	count, err := exts[3].Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, &SpotXCountExtension{TotalAvailable: 2}, count)
+	}
+}
+
+// registerTestExtension registers an extension type for the duration of the
+// test, restoring the previous registration afterwards
+func registerTestExtension(t *testing.T, typ string, newValue func() interface{}) {
+	extensionsMu.RLock()
+	prev, found := extensions[typ]
+	extensionsMu.RUnlock()
+	t.Cleanup(func() {
+		extensionsMu.Lock()
+		defer extensionsMu.Unlock()
+		if found {
+			extensions[typ] = prev
+		} else {
+			delete(extensions, typ)
+		}
+	})
+	RegisterExtension(typ, newValue)
+}
+
+func TestCreativeExtensions(t *testing.T) {
+	v, err := loadFixture("testdata/vast_extensions.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ce := v.Ads[0].InLine.Creatives[0].Linear.CreativeExtensions
+	if assert.NotNil(t, ce) && assert.Len(t, ce.Extensions, 1) {
+		ext := ce.Extensions[0]
+		assert.Equal(t, "CreativeExtension", ext.XMLName.Local)
+		assert.Equal(t, "application/javascript", ext.Type)
+		assert.Equal(t, "<![CDATA[var x = 1;]]>", string(ext.Data))
+
+		type script struct {
+			APIFramework string `xml:"apiFramework,attr"`
+			Code         string `xml:",chardata"`
+		}
+		registerTestExtension(t, "application/javascript", func() interface{} { return &script{} })
+		s, err := ext.Decode()
+		if assert.NoError(t, err) {
+			assert.Equal(t, &script{APIFramework: "custom", Code: "var x = 1;"}, s)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package vast
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the macros supported by the Macros typed setters.
+const (
+	MacroErrorCode       = "ERRORCODE"
+	MacroContentPlayhead = "CONTENTPLAYHEAD"
+	MacroAdPlayhead      = "ADPLAYHEAD"
+	MacroCacheBusting    = "CACHEBUSTING"
+	MacroTimestamp       = "TIMESTAMP"
+	MacroAssetURI        = "ASSETURI"
+	MacroPrice           = "PRICE"
+)
+
+var macroRe = regexp.MustCompile(`\[([A-Z][A-Z0-9_]*)\]|%%([A-Z][A-Z0-9_]*)%%`)
+
+// Macros holds the values substituted to the IAB macros found in VAST URIs
+// such as Tracking.URI, Impression.URI or error URIs.
+//
+// Both the [MACRO] and %%MACRO%% forms are expanded. The zero value is ready to use.
+type Macros struct {
+	// BlankUnknown replaces the macros with no value by an empty string.
+	// By default, such macros are left intact.
+	BlankUnknown bool
+
+	values map[string]string
+}
+
+// Set sets the raw (non encoded) value of the named macro.
+func (m *Macros) Set(name, value string) {
+	if m.values == nil {
+		m.values = map[string]string{}
+	}
+	m.values[name] = value
+}
+
+// Get returns the raw value of the named macro, if set.
+func (m *Macros) Get(name string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	v, ok := m.values[name]
+	return v, ok
+}
+
+// clone returns a copy of m which can be modified without affecting m. A nil
+// m returns empty macros.
+func (m *Macros) clone() *Macros {
+	c := &Macros{}
+	if m == nil {
+		return c
+	}
+	c.BlankUnknown = m.BlankUnknown
+	for k, v := range m.values {
+		c.Set(k, v)
+	}
+	return c
+}
+
+// SetErrorCode sets the [ERRORCODE] macro.
+func (m *Macros) SetErrorCode(code int) {
+	m.Set(MacroErrorCode, strconv.Itoa(code))
+}
+
+// SetContentPlayhead sets the [CONTENTPLAYHEAD] macro to the playhead of the
+// content video.
+func (m *Macros) SetContentPlayhead(d time.Duration) {
+	m.Set(MacroContentPlayhead, formatPlayhead(d))
+}
+
+// SetAdPlayhead sets the [ADPLAYHEAD] macro to the playhead of the ad.
+func (m *Macros) SetAdPlayhead(d time.Duration) {
+	m.Set(MacroAdPlayhead, formatPlayhead(d))
+}
+
+// SetCacheBusting sets the [CACHEBUSTING] macro. When not set, a new random
+// 8 digits number is used on each Expand call.
+func (m *Macros) SetCacheBusting(n int) {
+	m.Set(MacroCacheBusting, fmt.Sprintf("%08d", n))
+}
+
+// SetTimestamp sets the [TIMESTAMP] macro to t using ISO 8601 format.
+func (m *Macros) SetTimestamp(t time.Time) {
+	m.Set(MacroTimestamp, t.Format("2006-01-02T15:04:05.000Z07:00"))
+}
+
+// SetAssetURI sets the [ASSETURI] macro to the URI of the played media file.
+func (m *Macros) SetAssetURI(uri string) {
+	m.Set(MacroAssetURI, uri)
+}
+
+// Expand returns uri with all its macros replaced by their URL-encoded value.
+// A nil m expands like empty macros.
+func (m *Macros) Expand(uri string) string {
+	if m == nil {
+		m = &Macros{}
+	}
+	uri = strings.TrimSpace(uri)
+	return macroRe.ReplaceAllStringFunc(uri, func(match string) string {
+		name := strings.Trim(match, "[]%")
+		v, ok := m.values[name]
+		if !ok && name == MacroCacheBusting {
+			v, ok = fmt.Sprintf("%08d", rand.Intn(100000000)), true
+		}
+		if !ok {
+			if m.BlankUnknown {
+				return ""
+			}
+			return match
+		}
+		return encodeMacroValue(v)
+	})
+}
+
+// formatPlayhead formats d as HH:MM:SS.mmm
+func formatPlayhead(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d.%03d",
+		d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second, d%time.Second/time.Millisecond)
+}
+
+// encodeMacroValue percent-encodes all but the unreserved characters of
+// RFC 3986 as required by the VAST spec
+func encodeMacroValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
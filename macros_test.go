@@ -0,0 +1,49 @@
+package vast
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMacrosExpand(t *testing.T) {
+	var m Macros
+	m.SetErrorCode(303)
+	m.SetContentPlayhead(time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond)
+	m.SetAdPlayhead(15 * time.Second)
+	m.SetCacheBusting(1234)
+	m.SetTimestamp(time.Date(2016, 1, 17, 8, 15, 7, 127000000, time.FixedZone("", 5*3600)))
+	m.SetAssetURI("http://cdn.example.com/video.mp4?a=b c")
+
+	assert.Equal(t, "http://t/e?code=303", m.Expand("http://t/e?code=[ERRORCODE]"))
+	assert.Equal(t, "http://t/e?code=303", m.Expand("http://t/e?code=%%ERRORCODE%%"))
+	assert.Equal(t, "http://t/e?code=303", m.Expand("\n  http://t/e?code=[ERRORCODE]\n"))
+	assert.Equal(t, "http://t/p?c=01%3A02%3A03.004&a=00%3A00%3A15.000", m.Expand("http://t/p?c=[CONTENTPLAYHEAD]&a=[ADPLAYHEAD]"))
+	assert.Equal(t, "http://t/?cb=00001234", m.Expand("http://t/?cb=[CACHEBUSTING]"))
+	assert.Equal(t, "http://t/?ts=2016-01-17T08%3A15%3A07.127%2B05%3A00", m.Expand("http://t/?ts=[TIMESTAMP]"))
+	assert.Equal(t, "http://t/?u=http%3A%2F%2Fcdn.example.com%2Fvideo.mp4%3Fa%3Db%20c", m.Expand("http://t/?u=[ASSETURI]"))
+
+	m.Set("CUSTOM", "a/b")
+	if v, ok := m.Get("CUSTOM"); assert.True(t, ok) {
+		assert.Equal(t, "a/b", v)
+	}
+	assert.Equal(t, "http://t/?c=a%2Fb", m.Expand("http://t/?c=%%CUSTOM%%"))
+}
+
+func TestMacrosUnknown(t *testing.T) {
+	var m Macros
+	assert.Equal(t, "http://t/?e=[ERRORCODE]&x=%%FOO%%&y=[lower]", m.Expand("http://t/?e=[ERRORCODE]&x=%%FOO%%&y=[lower]"))
+	m.BlankUnknown = true
+	assert.Equal(t, "http://t/?e=&x=&y=[lower]", m.Expand("http://t/?e=[ERRORCODE]&x=%%FOO%%&y=[lower]"))
+	assert.Regexp(t, regexp.MustCompile(`^http://t/\?cb=\d{8}$`), m.Expand("http://t/?cb=[CACHEBUSTING]"))
+}
+
+func TestMacrosNil(t *testing.T) {
+	var m *Macros
+	assert.Equal(t, "http://t/?e=[ERRORCODE]", m.Expand(" http://t/?e=[ERRORCODE] "))
+	assert.Regexp(t, regexp.MustCompile(`^http://t/\?cb=\d{8}$`), m.Expand("http://t/?cb=[CACHEBUSTING]"))
+	_, ok := m.Get(MacroErrorCode)
+	assert.False(t, ok)
+}
@@ -0,0 +1,160 @@
+package vast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Weights of the media file scoring criteria
+const (
+	sizeWeight    = 50
+	bitrateWeight = 30
+	scaleWeight   = 20
+)
+
+// MediaCriteria describes the media files a player is able to play and the
+// ones it prefers.
+type MediaCriteria struct {
+	// Accepted MIME types, "video/*" style wildcards are supported. If empty,
+	// all types are accepted.
+	Types []string
+	// Accepted delivery methods, "progressive" and/or "streaming". If empty,
+	// all delivery methods are accepted.
+	Delivery []string
+	// API frameworks supported by the player (e.g. "VPAID"). Media files
+	// requiring another framework are rejected. Media files without
+	// apiFramework are always accepted.
+	APIFrameworks []string
+	// Pixel dimensions of the player. If zero, sizes are not scored.
+	Width, Height int
+	// Available bandwidth in Kbps. If zero, bitrates are not scored.
+	Bandwidth int
+}
+
+// RankedMediaFile is a media file matching a MediaCriteria along with its score
+type RankedMediaFile struct {
+	MediaFile *MediaFile
+	// The higher, the better
+	Score float64
+	// Explanations of how the score was computed
+	Reasons []string
+}
+
+// SelectMediaFile returns the media files matching the MIME type, delivery and
+// API framework of c, best first.
+//
+// Media files are scored on how close their size is to the player size, how
+// well their bitrate fits the available bandwidth and whether they can be
+// scaled to the player size without distortion.
+func SelectMediaFile(files []*MediaFile, c MediaCriteria) []*RankedMediaFile {
+	var ranked []*RankedMediaFile
+	for _, mf := range files {
+		if !matchMIMEType(c.Types, mf.Type) ||
+			!matchAny(c.Delivery, mf.Delivery) ||
+			(mf.APIFramework != "" && (len(c.APIFrameworks) == 0 || !matchAny(c.APIFrameworks, mf.APIFramework))) {
+			continue
+		}
+		r := &RankedMediaFile{MediaFile: mf}
+		r.scoreSize(c)
+		r.scoreBitrate(c)
+		ranked = append(ranked, r)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
+
+func (r *RankedMediaFile) add(score float64, format string, args ...interface{}) {
+	r.Score += score
+	r.Reasons = append(r.Reasons, fmt.Sprintf("%+.1f: ", score)+fmt.Sprintf(format, args...))
+}
+
+func (r *RankedMediaFile) scoreSize(c MediaCriteria) {
+	mf := r.MediaFile
+	if c.Width <= 0 || c.Height <= 0 || mf.Width <= 0 || mf.Height <= 0 {
+		return
+	}
+	area := float64(mf.Width * mf.Height)
+	target := float64(c.Width * c.Height)
+	ratio := area / target
+	if ratio > 1 {
+		ratio = 1 / ratio
+	}
+	r.add(sizeWeight*ratio, "%dx%d covers %.0f%% of the %dx%d player", mf.Width, mf.Height, 100*area/target, c.Width, c.Height)
+	if mf.Width == c.Width && mf.Height == c.Height {
+		r.add(scaleWeight, "no scaling needed")
+		return
+	}
+	if !mf.Scalable {
+		r.add(0, "not scalable")
+		return
+	}
+	sameAspect := mf.Width*c.Height == mf.Height*c.Width
+	if mf.MaintainAspectRatio || sameAspect {
+		r.add(scaleWeight, "scalable without distortion")
+	} else {
+		r.add(scaleWeight/2, "scalable with aspect ratio distortion")
+	}
+}
+
+func (r *RankedMediaFile) scoreBitrate(c MediaCriteria) {
+	mf := r.MediaFile
+	if c.Bandwidth <= 0 {
+		return
+	}
+	bw := float64(c.Bandwidth)
+	switch {
+	case mf.MinBitrate > 0 && mf.MaxBitrate > 0:
+		switch {
+		case mf.MinBitrate <= c.Bandwidth && c.Bandwidth <= mf.MaxBitrate:
+			r.add(bitrateWeight, "adaptive bitrate %d-%d Kbps fits %d Kbps bandwidth", mf.MinBitrate, mf.MaxBitrate, c.Bandwidth)
+		case c.Bandwidth > mf.MaxBitrate:
+			r.add(bitrateWeight*float64(mf.MaxBitrate)/bw, "adaptive bitrate %d-%d Kbps underuses %d Kbps bandwidth", mf.MinBitrate, mf.MaxBitrate, c.Bandwidth)
+		default:
+			r.add(bitrateWeight/2*bw/float64(mf.MinBitrate), "adaptive bitrate %d-%d Kbps exceeds %d Kbps bandwidth", mf.MinBitrate, mf.MaxBitrate, c.Bandwidth)
+		}
+	case mf.Bitrate > 0:
+		br := float64(mf.Bitrate)
+		if br <= bw {
+			r.add(bitrateWeight*br/bw, "bitrate %d Kbps fits %d Kbps bandwidth", mf.Bitrate, c.Bandwidth)
+		} else {
+			// Buffering is worse than a lower quality: penalize more
+			r.add(bitrateWeight/2*bw/br, "bitrate %d Kbps exceeds %d Kbps bandwidth", mf.Bitrate, c.Bandwidth)
+		}
+	default:
+		r.add(bitrateWeight/2, "unknown bitrate")
+	}
+}
+
+// matchAny tells if v is one of values, ignoring case. An empty values
+// matches everything.
+func matchAny(values []string, v string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	v = strings.TrimSpace(v)
+	for _, val := range values {
+		if strings.EqualFold(val, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchMIMEType tells if the MIME type t is matched by one of types, which
+// can contain "video/*" style wildcards. An empty types matches everything.
+func matchMIMEType(types []string, t string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	t = strings.ToLower(strings.TrimSpace(t))
+	for _, pattern := range types {
+		pattern = strings.ToLower(pattern)
+		if pattern == t || (strings.HasSuffix(pattern, "/*") && strings.HasPrefix(t, pattern[:len(pattern)-1])) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectMediaFileFilters(t *testing.T) {
+	files := []*MediaFile{
+		{ID: "flv", Delivery: "progressive", Type: "video/x-flv", Width: 640, Height: 360},
+		{ID: "mp4", Delivery: "progressive", Type: "video/mp4", Width: 640, Height: 360},
+		{ID: "hls", Delivery: "streaming", Type: "application/x-mpegURL", Width: 640, Height: 360},
+		{ID: "vpaid", Delivery: "progressive", Type: "application/javascript", APIFramework: "VPAID", Width: 640, Height: 360},
+	}
+	ids := func(ranked []*RankedMediaFile) []string {
+		var ids []string
+		for _, r := range ranked {
+			ids = append(ids, r.MediaFile.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"flv", "mp4", "hls"}, ids(SelectMediaFile(files, MediaCriteria{})))
+	assert.Equal(t, []string{"flv", "mp4"}, ids(SelectMediaFile(files, MediaCriteria{Types: []string{"video/*"}})))
+	assert.Equal(t, []string{"hls"}, ids(SelectMediaFile(files, MediaCriteria{Delivery: []string{"streaming"}})))
+	assert.Equal(t, []string{"mp4", "vpaid"}, ids(SelectMediaFile(files, MediaCriteria{
+		Types:         []string{"video/MP4", "application/javascript"},
+		APIFrameworks: []string{"vpaid"},
+	})))
+}
+
+func TestSelectMediaFileRanking(t *testing.T) {
+	files := []*MediaFile{
+		{ID: "small", Type: "video/mp4", Width: 320, Height: 180, Bitrate: 300, Scalable: true, MaintainAspectRatio: true},
+		{ID: "exact", Type: "video/mp4", Width: 1280, Height: 720, Bitrate: 2500},
+		{ID: "hd", Type: "video/mp4", Width: 1280, Height: 720, Bitrate: 1200},
+		{ID: "adaptive", Type: "video/mp4", Width: 1280, Height: 720, MinBitrate: 500, MaxBitrate: 3000},
+		{ID: "stretched", Type: "video/mp4", Width: 640, Height: 480, Bitrate: 1000, Scalable: true},
+	}
+	ranked := SelectMediaFile(files, MediaCriteria{Width: 1280, Height: 720, Bandwidth: 1500})
+	if assert.Len(t, ranked, 5) {
+		assert.Equal(t, "adaptive", ranked[0].MediaFile.ID)
+		assert.Equal(t, 100.0, ranked[0].Score)
+		assert.Equal(t, []string{
+			"+50.0: 1280x720 covers 100% of the 1280x720 player",
+			"+20.0: no scaling needed",
+			"+30.0: adaptive bitrate 500-3000 Kbps fits 1500 Kbps bandwidth",
+		}, ranked[0].Reasons)
+		assert.Equal(t, "hd", ranked[1].MediaFile.ID)
+		assert.Equal(t, "exact", ranked[2].MediaFile.ID)
+		assert.Equal(t, "stretched", ranked[3].MediaFile.ID)
+		assert.Contains(t, ranked[3].Reasons, "+10.0: scalable with aspect ratio distortion")
+		assert.Equal(t, "small", ranked[4].MediaFile.ID)
+	}
+}
@@ -0,0 +1,188 @@
+package vast
+
+import "strings"
+
+// MergeWrapper returns a copy of in with the trackers of the wrapper w added to it.
+//
+// Impressions and errors are merged at the ad level. Each wrapper creative is
+// matched with an InLine creative of the same kind (linear, non-linear or companion)
+// by AdID first, then by sequence, then by position, and finally with the first
+// creative of the same kind. Linear tracking events and click trackings, non-linear
+// tracking events and companion tracking events and click trackings of the matched
+// wrapper creative are then appended to the InLine creative ones. Wrapper creatives
+// which cannot be matched are ignored.
+//
+// The click and view trackings of wrapper icons are appended to the InLine icon
+// of the same program. Wrapper icons with a resource and no matching InLine icon
+// are added to the InLine creative.
+//
+// The verifications of the wrapper, including the ones provided as an
+// AdVerifications extension, are added after the InLine ones, skipping the
+// verifications with the same vendor and resources.
+//
+// Neither w nor in are modified.
+func MergeWrapper(w *Wrapper, in *InLine) *InLine {
+	m := *in
+	m.Impressions = append(append([]*Impression{}, in.Impressions...), w.Impressions...)
+	m.Errors = append(append([]string{}, in.Errors...), w.Errors...)
+	m.AdVerifications = appendVerifications(nil, in.AdVerifications)
+	m.AdVerifications = appendVerifications(m.AdVerifications, extensionVerifications(in.Extensions))
+	m.AdVerifications = appendVerifications(m.AdVerifications, w.AdVerifications)
+	m.AdVerifications = appendVerifications(m.AdVerifications, extensionVerifications(w.Extensions))
+	m.Creatives = make([]*Creative, len(in.Creatives))
+	for i, c := range in.Creatives {
+		m.Creatives[i] = copyCreative(c)
+	}
+	for i, cw := range w.Creatives {
+		c := matchCreative(cw, i, m.Creatives)
+		if c == nil {
+			continue
+		}
+		if cw.Linear != nil && c.Linear != nil {
+			mergeLinear(cw.Linear, c.Linear)
+		}
+		if cw.NonLinearAds != nil && c.NonLinearAds != nil {
+			c.NonLinearAds.TrackingEvents = append(c.NonLinearAds.TrackingEvents, cw.NonLinearAds.TrackingEvents...)
+		}
+		if cw.CompanionAds != nil && c.CompanionAds != nil {
+			mergeCompanions(cw.CompanionAds, c.CompanionAds)
+		}
+	}
+	return &m
+}
+
+// Flatten returns a copy of the resolved ad with the trackers of all its
+// wrappers merged into its InLine element. It returns nil if the ad failed to
+// resolve.
+func (r *ResolvedAd) Flatten() *Ad {
+	if r.Err != nil || r.Ad.InLine == nil {
+		return nil
+	}
+	ad := *r.Ad
+	for i := len(r.Wrappers) - 1; i >= 0; i-- {
+		ad.InLine = MergeWrapper(r.Wrappers[i].Wrapper, ad.InLine)
+	}
+	return &ad
+}
+
+// copyCreative copies the parts of a creative MergeWrapper may modify
+func copyCreative(c *Creative) *Creative {
+	cc := *c
+	if c.Linear != nil {
+		l := *c.Linear
+		l.TrackingEvents = append([]*Tracking{}, l.TrackingEvents...)
+		if len(l.Icons) > 0 {
+			l.Icons = make([]*Icon, len(c.Linear.Icons))
+			for i, icon := range c.Linear.Icons {
+				ic := *icon
+				ic.IconClickTrackings = append([]string{}, ic.IconClickTrackings...)
+				ic.IconViewTrackings = append([]string{}, ic.IconViewTrackings...)
+				l.Icons[i] = &ic
+			}
+		}
+		if l.VideoClicks != nil {
+			vc := *l.VideoClicks
+			vc.ClickTrackings = append([]*VideoClick{}, vc.ClickTrackings...)
+			l.VideoClicks = &vc
+		}
+		cc.Linear = &l
+	}
+	if c.NonLinearAds != nil {
+		nl := *c.NonLinearAds
+		nl.TrackingEvents = append([]*Tracking{}, nl.TrackingEvents...)
+		cc.NonLinearAds = &nl
+	}
+	if c.CompanionAds != nil {
+		ca := *c.CompanionAds
+		ca.Companions = make([]*Companion, len(c.CompanionAds.Companions))
+		for i, comp := range c.CompanionAds.Companions {
+			cp := *comp
+			cp.TrackingEvents = append([]*Tracking{}, cp.TrackingEvents...)
+			cp.CompanionClickTracking = append([]string{}, cp.CompanionClickTracking...)
+			ca.Companions[i] = &cp
+		}
+		cc.CompanionAds = &ca
+	}
+	return &cc
+}
+
+// sameKind tells if the wrapper creative cw holds trackers applicable to c
+func sameKind(cw *CreativeWrapper, c *Creative) bool {
+	return (cw.Linear != nil && c.Linear != nil) ||
+		(cw.NonLinearAds != nil && c.NonLinearAds != nil) ||
+		(cw.CompanionAds != nil && c.CompanionAds != nil)
+}
+
+func matchCreative(cw *CreativeWrapper, pos int, creatives []*Creative) *Creative {
+	if cw.AdID != "" {
+		for _, c := range creatives {
+			if c.AdID == cw.AdID && sameKind(cw, c) {
+				return c
+			}
+		}
+	}
+	if cw.Sequence != 0 {
+		for _, c := range creatives {
+			if c.Sequence == cw.Sequence && sameKind(cw, c) {
+				return c
+			}
+		}
+	}
+	if pos < len(creatives) && sameKind(cw, creatives[pos]) {
+		return creatives[pos]
+	}
+	for _, c := range creatives {
+		if sameKind(cw, c) {
+			return c
+		}
+	}
+	return nil
+}
+
+func mergeLinear(lw *LinearWrapper, l *Linear) {
+	l.TrackingEvents = append(l.TrackingEvents, lw.TrackingEvents...)
+	mergeIcons(lw.Icons, l)
+	if lw.VideoClicks != nil && len(lw.VideoClicks.ClickTrackings) > 0 {
+		if l.VideoClicks == nil {
+			l.VideoClicks = &VideoClicks{}
+		}
+		l.VideoClicks.ClickTrackings = append(l.VideoClicks.ClickTrackings, lw.VideoClicks.ClickTrackings...)
+	}
+}
+
+// mergeCompanions adds the trackers of each wrapper companion to the InLine
+// companion with the same id, slot id or dimensions
+func mergeCompanions(caw *CompanionAdsWrapper, ca *CompanionAds) {
+	for _, cw := range caw.Companions {
+		for _, c := range ca.Companions {
+			if (cw.ID != "" && cw.ID == c.ID) ||
+				(cw.AdSlotID != "" && cw.AdSlotID == c.AdSlotID) ||
+				(cw.ID == "" && cw.AdSlotID == "" && cw.Width == c.Width && cw.Height == c.Height) {
+				c.TrackingEvents = append(c.TrackingEvents, cw.TrackingEvents...)
+				c.CompanionClickTracking = append(c.CompanionClickTracking, cw.CompanionClickTracking...)
+				break
+			}
+		}
+	}
+}
+
+// mergeIcons adds the trackers of each wrapper icon to the InLine icon of the
+// same program
+func mergeIcons(icons []*Icon, l *Linear) {
+	for _, iw := range icons {
+		var match *Icon
+		for _, i := range l.Icons {
+			if iw.Program != "" && strings.EqualFold(iw.Program, i.Program) {
+				match = i
+				break
+			}
+		}
+		switch {
+		case match != nil:
+			match.IconClickTrackings = append(match.IconClickTrackings, iw.IconClickTrackings...)
+			match.IconViewTrackings = append(match.IconViewTrackings, iw.IconViewTrackings...)
+		case iw.StaticResource != nil || iw.IFrameResource != "" || iw.HTMLResource != nil:
+			l.Icons = append(l.Icons, iw)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeWrapperLinear(t *testing.T) {
+	w, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	in := MergeWrapper(w.Ads[0].Wrapper, v.Ads[0].InLine)
+	if assert.Len(t, in.Impressions, 3) {
+		assert.Equal(t, "http://myTrackingURL/impression", in.Impressions[0].URI)
+		assert.Equal(t, "http://myTrackingURL/wrapper/impression", in.Impressions[2].URI)
+	}
+	assert.Equal(t, []string{"http://myErrorURL/error", "http://myErrorURL/error2", "http://myErrorURL/wrapper/error"}, in.Errors)
+	if assert.Len(t, in.Creatives, 2) {
+		linear := in.Creatives[0].Linear
+		if assert.Len(t, linear.TrackingEvents, 17) {
+			assert.Equal(t, "http://myTrackingURL/creativeView", linear.TrackingEvents[0].URI)
+			assert.Equal(t, "http://myTrackingURL/wrapper/creativeView", linear.TrackingEvents[6].URI)
+		}
+		if assert.Len(t, linear.VideoClicks.ClickTrackings, 2) {
+			assert.Equal(t, "http://myTrackingURL/click", linear.VideoClicks.ClickTrackings[0].URI)
+			assert.Equal(t, "http://myTrackingURL/wrapper/click", linear.VideoClicks.ClickTrackings[1].URI)
+		}
+		assert.Len(t, linear.MediaFiles, 1)
+		assert.Nil(t, in.Creatives[1].NonLinearAds)
+	}
+
+	// Sources are left untouched
+	assert.Len(t, v.Ads[0].InLine.Impressions, 2)
+	assert.Len(t, v.Ads[0].InLine.Creatives[0].Linear.TrackingEvents, 6)
+	assert.Len(t, v.Ads[0].InLine.Creatives[0].Linear.VideoClicks.ClickTrackings, 1)
+}
+
+func TestMergeWrapperCompanion(t *testing.T) {
+	w, err := loadFixture("testdata/vast_wrapper_linear_2.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	w.Ads[0].Wrapper.Creatives[1].CompanionAds.Companions[1].CompanionClickTracking = []string{"http://myTrackingURL/wrapper/companionClick"}
+
+	in := MergeWrapper(w.Ads[0].Wrapper, v.Ads[0].InLine)
+	if assert.Len(t, in.Creatives, 2) {
+		comps := in.Creatives[1].CompanionAds.Companions
+		if assert.Len(t, comps, 2) {
+			if assert.Len(t, comps[0].TrackingEvents, 2) {
+				assert.Equal(t, "http://myTrackingURL/wrapper/firstCompanionCreativeView", comps[0].TrackingEvents[1].URI)
+			}
+			assert.Len(t, comps[0].CompanionClickTracking, 0)
+			assert.Equal(t, []string{"http://myTrackingURL/wrapper/companionClick"}, comps[1].CompanionClickTracking)
+		}
+	}
+	assert.Len(t, v.Ads[0].InLine.Creatives[1].CompanionAds.Companions[0].TrackingEvents, 1)
+}
+
+func TestMergeWrapperIcons(t *testing.T) {
+	var in InLine
+	err := xml.Unmarshal([]byte(`<InLine><Creatives><Creative><Linear><Icons>
+		<Icon program="AdChoices" width="20" height="20" xPosition="right" yPosition="top">
+			<StaticResource creativeType="image/png"><![CDATA[http://t/adchoices.png]]></StaticResource>
+			<IconClicks><IconClickTracking><![CDATA[http://t/click]]></IconClickTracking></IconClicks>
+			<IconViewTracking><![CDATA[http://t/view]]></IconViewTracking>
+		</Icon>
+	</Icons></Linear></Creative></Creatives></InLine>`), &in)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var w Wrapper
+	err = xml.Unmarshal([]byte(`<Wrapper><Creatives><Creative><Linear><Icons>
+		<Icon program="adchoices">
+			<IconClicks><IconClickTracking><![CDATA[http://t/wrapper/click]]></IconClickTracking></IconClicks>
+			<IconViewTracking><![CDATA[http://t/wrapper/view]]></IconViewTracking>
+		</Icon>
+		<Icon program="Other"><IconViewTracking><![CDATA[http://t/other/view]]></IconViewTracking></Icon>
+		<Icon program="Logo"><IFrameResource><![CDATA[http://t/logo.html]]></IFrameResource></Icon>
+	</Icons></Linear></Creative></Creatives></Wrapper>`), &w)
+	if !assert.NoError(t, err) {
+		return
+	}
+	m := MergeWrapper(&w, &in)
+	icons := m.Creatives[0].Linear.Icons
+	if assert.Len(t, icons, 2) {
+		assert.Equal(t, "right", icons[0].XPosition)
+		assert.Equal(t, "top", icons[0].YPosition)
+		assert.Equal(t, []string{"http://t/click", "http://t/wrapper/click"}, icons[0].IconClickTrackings)
+		assert.Equal(t, []string{"http://t/view", "http://t/wrapper/view"}, icons[0].IconViewTrackings)
+		assert.Equal(t, "Logo", icons[1].Program)
+	}
+	// Sources are left untouched
+	if assert.Len(t, in.Creatives[0].Linear.Icons, 1) {
+		assert.Len(t, in.Creatives[0].Linear.Icons[0].IconViewTrackings, 1)
+	}
+}
+
+func TestMergeWrapperSequence(t *testing.T) {
+	in := &InLine{Creatives: []*Creative{
+		{Sequence: 1, Linear: &Linear{}},
+		{Sequence: 2, Linear: &Linear{}},
+	}}
+	w := &Wrapper{Creatives: []*CreativeWrapper{
+		{Sequence: 2, Linear: &LinearWrapper{TrackingEvents: []*Tracking{{Event: "start", URI: "http://t/2"}}}},
+		{AdID: "unknown", NonLinearAds: &NonLinearAdsWrapper{TrackingEvents: []*Tracking{{Event: "start", URI: "http://t/nl"}}}},
+	}}
+	m := MergeWrapper(w, in)
+	assert.Len(t, m.Creatives[0].Linear.TrackingEvents, 0)
+	if assert.Len(t, m.Creatives[1].Linear.TrackingEvents, 1) {
+		assert.Equal(t, "http://t/2", m.Creatives[1].Linear.TrackingEvents[0].URI)
+	}
+}
+
+func TestResolvedAdFlatten(t *testing.T) {
+	w1, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	w2, err := loadFixture("testdata/vast_wrapper_linear_2.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	r := &ResolvedAd{Ad: v.Ads[0], Wrappers: []*Ad{w1.Ads[0], w2.Ads[0]}}
+	ad := r.Flatten()
+	assert.Equal(t, "601364", ad.ID)
+	if assert.Len(t, ad.InLine.Impressions, 4) {
+		assert.Equal(t, "http://myTrackingURL/wrapper/impression", ad.InLine.Impressions[3].URI)
+	}
+	assert.Len(t, ad.InLine.Creatives[0].Linear.TrackingEvents, 17)
+	assert.Len(t, ad.InLine.Creatives[1].CompanionAds.Companions[0].TrackingEvents, 2)
+	assert.Len(t, v.Ads[0].InLine.Impressions, 2)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Offset represents either a vast.Duration or a percentage of the video duration.
@@ -36,3 +37,12 @@ func (o *Offset) UnmarshalText(data []byte) error {
 	o.Duration = &d
 	return o.Duration.UnmarshalText(data)
 }
+
+// Resolve returns the offset as a duration from the beginning of a video of
+// the given total duration. Percent offsets are rounded to the millisecond.
+func (o Offset) Resolve(total time.Duration) time.Duration {
+	if o.Duration != nil {
+		return time.Duration(*o.Duration)
+	}
+	return time.Duration(float64(o.Percent) * float64(total)).Round(time.Millisecond)
+}
@@ -2,6 +2,7 @@ package vast
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -43,3 +44,11 @@ func TestOffsetUnmarshaler(t *testing.T) {
 	o = Offset{}
 	assert.EqualError(t, o.UnmarshalText([]byte("abc%")), "invalid offset: abc%")
 }
+
+func TestOffsetResolve(t *testing.T) {
+	d := Duration(5 * time.Second)
+	assert.Equal(t, 5*time.Second, Offset{Duration: &d}.Resolve(30*time.Second))
+	assert.Equal(t, 3*time.Second, Offset{Percent: .1}.Resolve(30*time.Second))
+	assert.Equal(t, 7500*time.Millisecond, Offset{Percent: .25}.Resolve(30*time.Second))
+	assert.Equal(t, time.Duration(0), Offset{Percent: .5}.Resolve(0))
+}
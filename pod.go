@@ -0,0 +1,94 @@
+package vast
+
+import (
+	"sort"
+	"time"
+)
+
+// Pod is an ad pod: the sequenced ads of a VAST response to be played back to
+// back, along with the stand-alone ads of the response, known as the ad buffet,
+// which may be used as substitutes.
+type Pod struct {
+	// The ads with a sequence attribute, sorted by ascending sequence
+	Ads []*Ad
+	// The ads without a sequence attribute, in document order
+	Buffet []*Ad
+}
+
+// Pod separates the sequenced ads of the document from the stand-alone ads.
+// Ads sharing the same sequence are kept in document order.
+func (v *VAST) Pod() *Pod {
+	p := &Pod{}
+	for _, ad := range v.Ads {
+		if ad.Sequence > 0 {
+			p.Ads = append(p.Ads, ad)
+		} else {
+			p.Buffet = append(p.Buffet, ad)
+		}
+	}
+	sort.SliceStable(p.Ads, func(i, j int) bool {
+		return p.Ads[i].Sequence < p.Ads[j].Sequence
+	})
+	return p
+}
+
+// IsPod tells if the response contains sequenced ads.
+func (p *Pod) IsPod() bool {
+	return len(p.Ads) > 0
+}
+
+// Duration returns the total duration of the linear creatives of the pod ads.
+// Ads with an unknown duration, such as unresolved wrappers, count as zero.
+func (p *Pod) Duration() time.Duration {
+	var d time.Duration
+	for _, ad := range p.Ads {
+		d += ad.duration()
+	}
+	return d
+}
+
+// Replace handles the failure of the pod ad at index i. As allowed by the
+// spec, the ad is replaced by the first ad of the buffet, which is returned
+// and removed from the buffet. When the buffet is empty, the failed ad is
+// removed from the pod so the playback can continue with the next ad, and nil
+// is returned.
+func (p *Pod) Replace(i int) *Ad {
+	if i < 0 || i >= len(p.Ads) {
+		return nil
+	}
+	if len(p.Buffet) == 0 {
+		p.Ads = append(p.Ads[:i], p.Ads[i+1:]...)
+		return nil
+	}
+	ad := p.Buffet[0]
+	p.Buffet = p.Buffet[1:]
+	p.Ads[i] = ad
+	return ad
+}
+
+// Abandon gives up the pod and falls back to the VAST 3 behavior of players
+// without pod support: the sequenced ads are dropped and the first ad of the
+// buffet is returned to be played alone, or nil if the buffet is empty.
+func (p *Pod) Abandon() *Ad {
+	p.Ads = nil
+	if len(p.Buffet) == 0 {
+		return nil
+	}
+	ad := p.Buffet[0]
+	p.Buffet = p.Buffet[1:]
+	return ad
+}
+
+// duration returns the total duration of the linear creatives of the InLine ad
+func (ad *Ad) duration() time.Duration {
+	if ad.InLine == nil {
+		return 0
+	}
+	var d time.Duration
+	for _, c := range ad.InLine.Creatives {
+		if c.Linear != nil {
+			d += c.Linear.duration()
+		}
+	}
+	return d
+}
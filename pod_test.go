@@ -0,0 +1,71 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func adIDs(ads []*Ad) []string {
+	ids := []string{}
+	for _, ad := range ads {
+		ids = append(ids, ad.ID)
+	}
+	return ids
+}
+
+func TestPod(t *testing.T) {
+	v, err := loadFixture("testdata/vast_pod.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	p := v.Pod()
+	assert.True(t, p.IsPod())
+	assert.Equal(t, []string{"pod-1", "pod-2", "pod-3"}, adIDs(p.Ads))
+	assert.Equal(t, []string{"buffet-1", "buffet-2"}, adIDs(p.Buffet))
+	assert.Equal(t, 45*time.Second, p.Duration())
+
+	v, err = loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	p = v.Pod()
+	assert.False(t, p.IsPod())
+	assert.Len(t, p.Buffet, 1)
+	assert.Equal(t, time.Duration(0), p.Duration())
+}
+
+func TestPodReplace(t *testing.T) {
+	v, err := loadFixture("testdata/vast_pod.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	p := v.Pod()
+	if ad := p.Replace(1); assert.NotNil(t, ad) {
+		assert.Equal(t, "buffet-1", ad.ID)
+	}
+	assert.Equal(t, []string{"pod-1", "buffet-1", "pod-3"}, adIDs(p.Ads))
+	assert.Equal(t, 25*time.Second, p.Duration())
+
+	assert.NotNil(t, p.Replace(2))
+	assert.Nil(t, p.Replace(0))
+	assert.Equal(t, []string{"buffet-1", "buffet-2"}, adIDs(p.Ads))
+	assert.Nil(t, p.Replace(5))
+	assert.Len(t, p.Ads, 2)
+}
+
+func TestPodAbandon(t *testing.T) {
+	v, err := loadFixture("testdata/vast_pod.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	p := v.Pod()
+	if ad := p.Abandon(); assert.NotNil(t, ad) {
+		assert.Equal(t, "buffet-1", ad.ID)
+	}
+	assert.False(t, p.IsPod())
+	assert.Equal(t, []string{"buffet-2"}, adIDs(p.Buffet))
+	assert.NotNil(t, p.Abandon())
+	assert.Nil(t, p.Abandon())
+}
@@ -0,0 +1,158 @@
+package vast
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrEmptyPod is returned by PodBuilder.Build when no ad satisfies the pod
+// constraints.
+var ErrEmptyPod = errors.New("vast: no ad fits the pod constraints")
+
+// PodConstraints restricts the ads chosen by a PodBuilder. Zero values mean no
+// restriction.
+type PodConstraints struct {
+	// The maximum number of ads in the pod
+	MaxAds int
+	// The minimum duration of an ad
+	MinAdDuration time.Duration
+	// The maximum duration of an ad
+	MaxAdDuration time.Duration
+	// The minimum number of ads between two ads of the same advertiser, as
+	// given by InLine.Advertiser and compared ignoring the case. Ads with no
+	// advertiser are never separated.
+	AdvertiserSeparation int
+}
+
+// PodBuilder fills an ad break of a given duration with ads taken from
+// several VAST responses, such as the responses of the demand partners of a
+// server-side ad insertion service.
+type PodBuilder struct {
+	duration    time.Duration
+	constraints PodConstraints
+	docs        []*VAST
+}
+
+// NewPodBuilder starts building a pod for a break of the given duration.
+func NewPodBuilder(duration time.Duration, c PodConstraints) *PodBuilder {
+	return &PodBuilder{duration: duration, constraints: c}
+}
+
+// Add adds the ads of a VAST response to the candidates of the pod. Responses
+// are considered in the order they are added, and the ads of each response
+// in pod order followed by its stand-alone ads.
+func (b *PodBuilder) Add(v *VAST) *PodBuilder {
+	b.docs = append(b.docs, v)
+	return b
+}
+
+// maxPodSearch bounds the number of combinations of candidates tried by
+// PodBuilder.Build
+const maxPodSearch = 100000
+
+// Build chooses the ads of the pod and returns them as a single VAST document
+// with their Sequence set to their position in the pod.
+//
+// The pod is the combination of candidates filling the most of the break
+// while satisfying the constraints, favoring the first candidates among
+// combinations of the same duration. The ads are kept in candidate order,
+// except when an ad must be moved later to separate it from an ad of the same
+// advertiser. The search is bounded, so with many candidates the best pod
+// found after a number of steps is returned. Only InLine ads with linear
+// creatives of known duration are candidates, so wrappers must be resolved
+// first. The document version is the highest version of the added responses,
+// but at least 3.0 which introduced pods.
+func (b *PodBuilder) Build() (*VAST, error) {
+	v := &VAST{Version: "3.0"}
+	s := &podSearch{b: b, budget: maxPodSearch}
+	for _, doc := range b.docs {
+		if compareVersions(doc.Version, v.Version) > 0 {
+			v.Version = doc.Version
+		}
+		p := doc.Pod()
+		for _, ad := range append(append([]*Ad{}, p.Ads...), p.Buffet...) {
+			if b.candidate(ad) {
+				s.candidates = append(s.candidates, ad)
+			}
+		}
+	}
+	// left[i] is the total duration of the candidates from i
+	s.left = make([]time.Duration, len(s.candidates)+1)
+	for i := len(s.candidates) - 1; i >= 0; i-- {
+		s.left[i] = s.left[i+1] + s.candidates[i].duration()
+	}
+
+	s.search(0, nil, 0)
+	if len(s.best) == 0 {
+		return nil, ErrEmptyPod
+	}
+	for _, c := range s.best {
+		ad := *c
+		ad.Sequence = len(v.Ads) + 1
+		v.Ads = append(v.Ads, &ad)
+	}
+	return v, nil
+}
+
+// candidate tells if ad can be part of the pod on its own
+func (b *PodBuilder) candidate(ad *Ad) bool {
+	if ad.InLine == nil {
+		return false
+	}
+	d := ad.duration()
+	return d > 0 && d <= b.duration &&
+		d >= b.constraints.MinAdDuration &&
+		(b.constraints.MaxAdDuration == 0 || d <= b.constraints.MaxAdDuration)
+}
+
+// arrange orders ads so the ads of the same advertiser are separated, keeping
+// their order when possible. It returns nil if they can't be separated.
+func (b *PodBuilder) arrange(ads []*Ad) []*Ad {
+	pod := make([]*Ad, 0, len(ads))
+	left := append([]*Ad{}, ads...)
+	for len(left) > 0 {
+		i := 0
+		for ; i < len(left); i++ {
+			if advertiserConflict(left[i], pod, b.constraints.AdvertiserSeparation) == nil {
+				break
+			}
+		}
+		if i == len(left) {
+			return nil
+		}
+		pod = append(pod, left[i])
+		left = append(left[:i], left[i+1:]...)
+	}
+	return pod
+}
+
+// podSearch searches the combination of candidates filling the most of the
+// break
+type podSearch struct {
+	b          *PodBuilder
+	candidates []*Ad
+	left       []time.Duration
+	budget     int
+	best       []*Ad
+	bestDur    time.Duration
+}
+
+// search tries the combinations of chosen with the candidates from i
+func (s *podSearch) search(i int, chosen []*Ad, total time.Duration) {
+	if total > s.bestDur {
+		if pod := s.b.arrange(chosen); pod != nil {
+			s.best, s.bestDur = pod, total
+		}
+	}
+	if i == len(s.candidates) || s.budget <= 0 ||
+		s.bestDur == s.b.duration || total+s.left[i] <= s.bestDur {
+		return
+	}
+	s.budget--
+	if max := s.b.constraints.MaxAds; max == 0 || len(chosen) < max {
+		if d := s.candidates[i].duration(); total+d <= s.b.duration {
+			s.search(i+1, append(chosen, s.candidates[i]), total+d)
+		}
+	}
+	s.search(i+1, chosen, total)
+}
@@ -0,0 +1,91 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func podAd(id, advertiser string, seq int, dur time.Duration) *Ad {
+	d := Duration(dur)
+	return &Ad{ID: id, Sequence: seq, InLine: &InLine{
+		Advertiser: advertiser,
+		Creatives:  []*Creative{{Linear: &Linear{Duration: &d}}},
+	}}
+}
+
+func TestPodBuilder(t *testing.T) {
+	a := &VAST{Version: "2.0", Ads: []*Ad{
+		podAd("a1", "acme", 0, 30*time.Second),
+		podAd("a2", "ACME", 0, 15*time.Second),
+	}}
+	b := &VAST{Version: "4.1", Ads: []*Ad{
+		podAd("b2", "globex", 2, 30*time.Second),
+		podAd("b1", "initech", 1, 20*time.Second),
+		{ID: "wrapper", Wrapper: &Wrapper{}},
+	}}
+
+	v, err := NewPodBuilder(90*time.Second, PodConstraints{AdvertiserSeparation: 1}).Add(a).Add(b).Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "4.1", v.Version)
+	// a1, b1 and b2 fill 80s of the break, a2 would leave it shorter
+	assert.Equal(t, []string{"a1", "b1", "b2"}, adIDs(v.Ads))
+	for i, ad := range v.Ads {
+		assert.Equal(t, i+1, ad.Sequence)
+	}
+	assert.Equal(t, 80*time.Second, v.Pod().Duration())
+	// Advertisers are compared ignoring the case, so a2 can't follow a1
+	v, err = NewPodBuilder(45*time.Second, PodConstraints{AdvertiserSeparation: 1}).Add(a).Build()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a1"}, adIDs(v.Ads))
+	}
+	// Source documents are left untouched
+	assert.Equal(t, 0, a.Ads[0].Sequence)
+	assert.Equal(t, 2, b.Ads[0].Sequence)
+}
+
+func TestPodBuilderConstraints(t *testing.T) {
+	v := &VAST{Version: "3.0", Ads: []*Ad{
+		podAd("a1", "acme", 0, 30*time.Second),
+		podAd("a2", "acme", 0, 15*time.Second),
+		podAd("b1", "initech", 0, 20*time.Second),
+		podAd("c1", "", 0, 10*time.Second),
+	}}
+
+	pod, err := NewPodBuilder(90*time.Second, PodConstraints{MaxAds: 2, MaxAdDuration: 25 * time.Second}).Add(v).Build()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a2", "b1"}, adIDs(pod.Ads))
+	}
+
+	_, err = NewPodBuilder(90*time.Second, PodConstraints{MinAdDuration: time.Minute}).Add(v).Build()
+	assert.Equal(t, ErrEmptyPod, err)
+	_, err = NewPodBuilder(5*time.Second, PodConstraints{}).Add(v).Build()
+	assert.Equal(t, ErrEmptyPod, err)
+}
+
+func TestPodBuilderFill(t *testing.T) {
+	v := &VAST{Version: "3.0", Ads: []*Ad{
+		podAd("a", "acme", 0, 35*time.Second),
+		podAd("b", "globex", 0, 30*time.Second),
+		podAd("c", "initech", 0, 30*time.Second),
+	}}
+	pod, err := NewPodBuilder(60*time.Second, PodConstraints{}).Add(v).Build()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"b", "c"}, adIDs(pod.Ads))
+		assert.Equal(t, 60*time.Second, pod.Pod().Duration())
+	}
+
+	// Ads of the same advertiser are moved apart
+	v.Ads = []*Ad{
+		podAd("a1", "acme", 0, 10*time.Second),
+		podAd("a2", "acme", 0, 10*time.Second),
+		podAd("b", "globex", 0, 10*time.Second),
+	}
+	pod, err = NewPodBuilder(30*time.Second, PodConstraints{AdvertiserSeparation: 1}).Add(v).Build()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a1", "b", "a2"}, adIDs(pod.Ads))
+	}
+}
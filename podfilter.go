@@ -0,0 +1,186 @@
+package vast
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// DropReason tells why PodFilter dropped an ad from a pod
+type DropReason int
+
+const (
+	// DropDuplicate is used for ads sharing a creative with a previous ad
+	DropDuplicate DropReason = iota
+	// DropAdvertiser is used for ads too close to an ad of the same advertiser
+	DropAdvertiser
+	// DropCategory is used for ads too close to an ad of the same category
+	DropCategory
+)
+
+// String implements the fmt.Stringer interface.
+func (r DropReason) String() string {
+	switch r {
+	case DropDuplicate:
+		return "duplicate creative"
+	case DropAdvertiser:
+		return "advertiser separation"
+	case DropCategory:
+		return "category separation"
+	}
+	return fmt.Sprintf("DropReason(%d)", int(r))
+}
+
+// DroppedAd reports an ad removed from a pod by PodFilter
+type DroppedAd struct {
+	Ad     *Ad
+	Reason DropReason
+	// The ad kept in the pod the dropped ad conflicts with
+	Conflict *Ad
+	// The duplicated creative identifier, advertiser or category
+	Detail string
+}
+
+// String implements the fmt.Stringer interface.
+func (d DroppedAd) String() string {
+	return fmt.Sprintf("ad %s dropped: %s with ad %s (%s)", d.Ad.ID, d.Reason, d.Conflict.ID, d.Detail)
+}
+
+// PodFilter removes duplicate creatives and competing ads from a pod. Zero
+// values disable the corresponding rule.
+type PodFilter struct {
+	// Dedup drops ads sharing a creative with a previous ad of the pod. Creatives
+	// are identified by their AdID, their UniversalAdId and their media file URIs.
+	Dedup bool
+	// The minimum number of ads between two ads of the same advertiser, as
+	// given by InLine.Advertiser
+	AdvertiserSeparation int
+	// The minimum number of ads between two ads of the same IAB category. A
+	// category conflicts with its sub-categories, e.g. "IAB2" with "IAB2-3".
+	CategorySeparation int
+}
+
+// Filter removes the ads of the pod breaking the rules of f and returns them
+// with the reason they were dropped. Ads are checked in pod order against the
+// ads kept before them. The sequence of the kept ads is left unchanged.
+func (p *Pod) Filter(f PodFilter) []DroppedAd {
+	var dropped []DroppedAd
+	var kept []*Ad
+	seen := map[string]*Ad{}
+	for _, ad := range p.Ads {
+		if d, ok := f.check(ad, kept, seen); ok {
+			dropped = append(dropped, d)
+			continue
+		}
+		kept = append(kept, ad)
+		if f.Dedup {
+			for _, k := range creativeKeys(ad) {
+				if _, found := seen[k]; !found {
+					seen[k] = ad
+				}
+			}
+		}
+	}
+	p.Ads = kept
+	return dropped
+}
+
+// check returns the reason why ad must be dropped, if any
+func (f PodFilter) check(ad *Ad, kept []*Ad, seen map[string]*Ad) (DroppedAd, bool) {
+	if f.Dedup {
+		for _, k := range creativeKeys(ad) {
+			if c, found := seen[k]; found {
+				return DroppedAd{Ad: ad, Reason: DropDuplicate, Conflict: c, Detail: k}, true
+			}
+		}
+	}
+	if ad.InLine == nil {
+		return DroppedAd{}, false
+	}
+	if c := advertiserConflict(ad, kept, f.AdvertiserSeparation); c != nil {
+		return DroppedAd{Ad: ad, Reason: DropAdvertiser, Conflict: c, Detail: ad.InLine.Advertiser}, true
+	}
+	for _, cat := range ad.InLine.Categories {
+		for _, c := range recent(kept, f.CategorySeparation) {
+			if c.InLine == nil {
+				continue
+			}
+			for _, ccat := range c.InLine.Categories {
+				if categoriesConflict(cat, ccat) {
+					return DroppedAd{Ad: ad, Reason: DropCategory, Conflict: c, Detail: strings.TrimSpace(cat.Code)}, true
+				}
+			}
+		}
+	}
+	return DroppedAd{}, false
+}
+
+// recent returns the last n ads of the pod
+func recent(ads []*Ad, n int) []*Ad {
+	if n > len(ads) {
+		n = len(ads)
+	}
+	return ads[len(ads)-n:]
+}
+
+// advertiserConflict returns the ad of the last n ads of the pod with the same
+// advertiser as ad, ignoring the case, or nil if none. Ads with no advertiser
+// never conflict.
+func advertiserConflict(ad *Ad, pod []*Ad, n int) *Ad {
+	adv := ad.InLine.Advertiser
+	if adv == "" {
+		return nil
+	}
+	for _, c := range recent(pod, n) {
+		if c.InLine != nil && strings.EqualFold(c.InLine.Advertiser, adv) {
+			return c
+		}
+	}
+	return nil
+}
+
+// categoriesConflict tells if a and b are the same category or if one is a
+// sub-category of the other
+func categoriesConflict(a, b *Category) bool {
+	if a.Authority != "" && b.Authority != "" && a.Authority != b.Authority {
+		return false
+	}
+	ac, bc := strings.TrimSpace(a.Code), strings.TrimSpace(b.Code)
+	if ac == "" || bc == "" {
+		return false
+	}
+	return ac == bc || strings.HasPrefix(ac, bc+"-") || strings.HasPrefix(bc, ac+"-")
+}
+
+// creativeKeys returns the keys identifying the creatives of the ad
+func creativeKeys(ad *Ad) []string {
+	if ad.InLine == nil {
+		return nil
+	}
+	var keys []string
+	for _, c := range ad.InLine.Creatives {
+		if c.AdID != "" {
+			keys = append(keys, "AdID:"+c.AdID)
+		}
+		for _, u := range c.UniversalAdIDs {
+			id := strings.TrimSpace(u.ID)
+			if id == "" {
+				id = u.IDValue
+			}
+			// "unknown" is used when the creative has no universal id
+			if id != "" && id != "unknown" {
+				keys = append(keys, "UniversalAdId:"+u.IDRegistry+"/"+id)
+			}
+		}
+		if c.Linear != nil {
+			for _, mf := range c.Linear.MediaFiles {
+				if uri := strings.TrimSpace(mf.URI); uri != "" {
+					h := fnv.New64a()
+					h.Write([]byte(uri))
+					keys = append(keys, fmt.Sprintf("MediaFile:%016x", h.Sum64()))
+				}
+			}
+		}
+	}
+	return keys
+}
@@ -0,0 +1,66 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodFilterDedup(t *testing.T) {
+	a := podAd("a", "", 1, 15*time.Second)
+	a.InLine.Creatives[0].AdID = "creative-1"
+	b := podAd("b", "", 2, 15*time.Second)
+	b.InLine.Creatives[0].AdID = "creative-1"
+	c := podAd("c", "", 3, 15*time.Second)
+	c.InLine.Creatives[0].UniversalAdIDs = []*UniversalAdID{{IDRegistry: "ad-id.org", ID: "unknown"}}
+	c.InLine.Creatives[0].Linear.MediaFiles = []*MediaFile{{URI: " http://cdn/video.mp4 "}}
+	d := podAd("d", "", 4, 15*time.Second)
+	d.InLine.Creatives[0].UniversalAdIDs = []*UniversalAdID{{IDRegistry: "ad-id.org", ID: "unknown"}}
+	d.InLine.Creatives[0].Linear.MediaFiles = []*MediaFile{{URI: "http://cdn/video.mp4"}}
+	e := podAd("e", "", 5, 15*time.Second)
+	e.InLine.Creatives[0].UniversalAdIDs = []*UniversalAdID{{IDRegistry: "ad-id.org", ID: "unknown"}}
+
+	p := &Pod{Ads: []*Ad{a, b, c, d, e}}
+	assert.Len(t, p.Filter(PodFilter{}), 0)
+
+	dropped := p.Filter(PodFilter{Dedup: true})
+	assert.Equal(t, []string{"a", "c", "e"}, adIDs(p.Ads))
+	if assert.Len(t, dropped, 2) {
+		assert.Equal(t, "ad b dropped: duplicate creative with ad a (AdID:creative-1)", dropped[0].String())
+		assert.Equal(t, DropDuplicate, dropped[1].Reason)
+		assert.Equal(t, c, dropped[1].Conflict)
+		assert.Regexp(t, `^MediaFile:[0-9a-f]{16}$`, dropped[1].Detail)
+	}
+}
+
+func TestPodFilterSeparation(t *testing.T) {
+	ford := podAd("ford", "Ford", 1, 15*time.Second)
+	ford.InLine.Categories = []*Category{{Code: "IAB2"}}
+	toyota := podAd("toyota", "Toyota", 2, 15*time.Second)
+	toyota.InLine.Categories = []*Category{{Code: "IAB2-3"}}
+	soda := podAd("soda", "Cola", 3, 15*time.Second)
+	soda.InLine.Categories = []*Category{{Code: "IAB8-5"}}
+	ford2 := podAd("ford2", "ford", 4, 15*time.Second)
+	ford2.InLine.Categories = []*Category{{Code: "IAB2"}}
+
+	p := &Pod{Ads: []*Ad{ford, toyota, soda, ford2}}
+	dropped := p.Filter(PodFilter{AdvertiserSeparation: 1, CategorySeparation: 1})
+	assert.Equal(t, []string{"ford", "soda", "ford2"}, adIDs(p.Ads))
+	if assert.Len(t, dropped, 1) {
+		assert.Equal(t, "ad toyota dropped: category separation with ad ford (IAB2-3)", dropped[0].String())
+	}
+
+	p = &Pod{Ads: []*Ad{ford, toyota, soda, ford2}}
+	dropped = p.Filter(PodFilter{AdvertiserSeparation: 2})
+	assert.Equal(t, []string{"ford", "toyota", "soda", "ford2"}, adIDs(p.Ads))
+	dropped = p.Filter(PodFilter{AdvertiserSeparation: 3})
+	assert.Equal(t, []string{"ford", "toyota", "soda"}, adIDs(p.Ads))
+	if assert.Len(t, dropped, 1) {
+		assert.Equal(t, DropAdvertiser, dropped[0].Reason)
+		assert.Equal(t, "ford", dropped[0].Detail)
+	}
+
+	assert.False(t, categoriesConflict(&Category{Authority: "a", Code: "IAB2"}, &Category{Authority: "b", Code: "IAB2"}))
+	assert.False(t, categoriesConflict(&Category{Code: "IAB2"}, &Category{Code: "IAB22"}))
+}
@@ -0,0 +1,125 @@
+package vast
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNoPricing is returned when an ad has no pricing information.
+	ErrNoPricing = errors.New("vast: no pricing")
+	// ErrInvalidPrice is returned when the value of a pricing is not a
+	// decimal number, e.g. because it is encrypted or an unexpanded macro.
+	ErrInvalidPrice = errors.New("vast: invalid price")
+)
+
+// CurrencyConverter converts value from a currency to another, both given as
+// ISO-4217 codes.
+type CurrencyConverter func(value float64, from, to string) (float64, error)
+
+// Float returns the value of the pricing as a decimal number. An
+// ErrInvalidPrice error is returned if the value is obfuscated.
+func (p *Pricing) Float() (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(p.Value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidPrice, p.Value)
+	}
+	return v, nil
+}
+
+// SetPrice sets the [PRICE] macro to the value of p. The macro is left unset
+// and an error is returned if p is nil or its value is not a decimal number.
+func (m *Macros) SetPrice(p *Pricing) error {
+	if p == nil {
+		return ErrNoPricing
+	}
+	v, err := p.Float()
+	if err != nil {
+		return err
+	}
+	m.Set(MacroPrice, strconv.FormatFloat(v, 'f', -1, 64))
+	return nil
+}
+
+// CPM returns the CPM of the pricing in the given currency. The value is
+// converted using conv when the currencies differ. An error is returned if the
+// pricing model is not "cpm", if the value is not a decimal number or if it
+// can't be converted.
+func (p *Pricing) CPM(currency string, conv CurrencyConverter) (float64, error) {
+	if p == nil {
+		return 0, ErrNoPricing
+	}
+	if !strings.EqualFold(p.Model, "cpm") {
+		return 0, fmt.Errorf("vast: unsupported pricing model %q", p.Model)
+	}
+	v, err := p.Float()
+	if err != nil {
+		return 0, err
+	}
+	if currency == "" || strings.EqualFold(p.Currency, currency) {
+		return v, nil
+	}
+	if conv == nil {
+		return 0, fmt.Errorf("vast: no converter from %s to %s", p.Currency, currency)
+	}
+	return conv(v, strings.ToUpper(p.Currency), strings.ToUpper(currency))
+}
+
+// CPM returns the CPM of the InLine ad in the given currency, as defined by
+// Pricing.CPM.
+func (ad *Ad) CPM(currency string, conv CurrencyConverter) (float64, error) {
+	if ad.InLine == nil {
+		return 0, ErrNoPricing
+	}
+	return ad.InLine.Pricing.CPM(currency, conv)
+}
+
+// CompareCPM compares the CPM of two ads in the given currency. It returns a
+// positive number if a is worth more than b, a negative number if b is worth
+// more than a and 0 otherwise. Ads whose CPM can't be computed are worth less
+// than any other ad.
+func CompareCPM(a, b *Ad, currency string, conv CurrencyConverter) int {
+	ac, aerr := a.CPM(currency, conv)
+	bc, berr := b.CPM(currency, conv)
+	switch {
+	case aerr != nil && berr != nil:
+		return 0
+	case aerr != nil:
+		return -1
+	case berr != nil:
+		return 1
+	case ac > bc:
+		return 1
+	case ac < bc:
+		return -1
+	}
+	return 0
+}
+
+// SortByCPM sorts ads by descending CPM in the given currency, using conv to
+// convert prices in other currencies. Ads whose CPM can't be computed are
+// moved to the end. The order of ads with the same CPM is preserved.
+func SortByCPM(ads []*Ad, currency string, conv CurrencyConverter) {
+	type priced struct {
+		ad  *Ad
+		cpm float64
+		ok  bool
+	}
+	p := make([]priced, len(ads))
+	for i, ad := range ads {
+		cpm, err := ad.CPM(currency, conv)
+		p[i] = priced{ad, cpm, err == nil}
+	}
+	sort.SliceStable(p, func(i, j int) bool {
+		if p[i].ok != p[j].ok {
+			return p[i].ok
+		}
+		return p[i].cpm > p[j].cpm
+	})
+	for i := range p {
+		ads[i] = p[i].ad
+	}
+}
@@ -0,0 +1,84 @@
+package vast
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pricedAd(id, model, currency, value string) *Ad {
+	return &Ad{ID: id, InLine: &InLine{Pricing: &Pricing{Model: model, Currency: currency, Value: value}}}
+}
+
+func eurToUSD(value float64, from, to string) (float64, error) {
+	if from == "EUR" && to == "USD" {
+		return value * 2, nil
+	}
+	return 0, fmt.Errorf("no rate from %s to %s", from, to)
+}
+
+func TestPricingCPM(t *testing.T) {
+	cpm, err := (&Pricing{Model: "CPM", Currency: "USD", Value: "12.5"}).CPM("usd", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 12.5, cpm)
+
+	cpm, err = (&Pricing{Model: "cpm", Currency: "EUR", Value: "10"}).CPM("USD", eurToUSD)
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, cpm)
+
+	_, err = (&Pricing{Model: "cpm", Currency: "EUR", Value: "10"}).CPM("USD", nil)
+	assert.EqualError(t, err, "vast: no converter from EUR to USD")
+	_, err = (&Pricing{Model: "cpc", Currency: "USD", Value: "1"}).CPM("USD", nil)
+	assert.EqualError(t, err, `vast: unsupported pricing model "cpc"`)
+	_, err = (*Pricing)(nil).CPM("USD", nil)
+	assert.Equal(t, ErrNoPricing, err)
+	_, err = (&Ad{Wrapper: &Wrapper{}}).CPM("USD", nil)
+	assert.Equal(t, ErrNoPricing, err)
+}
+
+func TestSortByCPM(t *testing.T) {
+	ads := []*Ad{
+		{ID: "none", InLine: &InLine{}},
+		pricedAd("usd10", "cpm", "USD", "10"),
+		pricedAd("cpc", "cpc", "USD", "50"),
+		pricedAd("eur7", "cpm", "EUR", "7"),
+		pricedAd("usd12", "cpm", "USD", "12"),
+		pricedAd("gbp", "cpm", "GBP", "100"),
+	}
+	SortByCPM(ads, "USD", eurToUSD)
+	var ids []string
+	for _, ad := range ads {
+		ids = append(ids, ad.ID)
+	}
+	assert.Equal(t, []string{"eur7", "usd12", "usd10", "none", "cpc", "gbp"}, ids)
+
+	assert.Equal(t, 1, CompareCPM(ads[0], ads[1], "USD", eurToUSD))
+	assert.Equal(t, -1, CompareCPM(ads[3], ads[2], "USD", eurToUSD))
+	assert.Equal(t, 0, CompareCPM(ads[3], ads[4], "USD", eurToUSD))
+}
+
+func TestMacrosSetPrice(t *testing.T) {
+	var m Macros
+	assert.Equal(t, ErrNoPricing, m.SetPrice(nil))
+	assert.EqualError(t, m.SetPrice(&Pricing{Model: "cpm", Currency: "USD", Value: "${AUCTION_PRICE}"}), `vast: invalid price: "${AUCTION_PRICE}"`)
+	assert.Equal(t, "http://t/?p=[PRICE]", m.Expand("http://t/?p=[PRICE]"))
+	assert.NoError(t, m.SetPrice(&Pricing{Model: "cpm", Currency: "USD", Value: " 25.50 "}))
+	assert.Equal(t, "http://t/?p=25.5", m.Expand("http://t/?p=[PRICE]"))
+}
+
+func TestPricingObfuscated(t *testing.T) {
+	var in InLine
+	err := xml.Unmarshal([]byte(`<InLine><Pricing model="cpm" currency="USD"><![CDATA[AhK3bFx9==]]></Pricing></InLine>`), &in)
+	if assert.NoError(t, err) && assert.NotNil(t, in.Pricing) {
+		assert.Equal(t, "AhK3bFx9==", in.Pricing.Value)
+		_, err = in.Pricing.Float()
+		assert.EqualError(t, err, `vast: invalid price: "AhK3bFx9=="`)
+		assert.True(t, errors.Is(err, ErrInvalidPrice))
+		_, err = in.Pricing.CPM("USD", nil)
+		assert.EqualError(t, err, `vast: invalid price: "AhK3bFx9=="`)
+		assert.True(t, errors.Is(err, ErrInvalidPrice))
+	}
+}
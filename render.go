@@ -0,0 +1,169 @@
+package vast
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// ErrUnsupportedResource is returned by Renderer when an element has no
+// resource which can be rendered as HTML.
+var ErrUnsupportedResource = errors.New("vast: unsupported resource")
+
+var renderTemplate = template.Must(template.New("").Parse(`
+{{- define "element" -}}
+<div class="{{.Class}}">
+{{- if .Image}}
+{{if .ClickThrough}}<a href="{{.ClickThrough}}" target="_blank" rel="noopener noreferrer">{{end -}}
+<img src="{{.Image}}"{{if .Width}} width="{{.Width}}"{{end}}{{if .Height}} height="{{.Height}}"{{end}} alt="{{.AltText}}">
+{{- if .ClickThrough}}</a>{{end}}
+{{- else if .IFrame}}
+<iframe src="{{.IFrame}}"{{if .Width}} width="{{.Width}}"{{end}}{{if .Height}} height="{{.Height}}"{{end}} frameborder="0" scrolling="no"></iframe>
+{{- else}}
+<iframe srcdoc="{{.HTML}}"{{if .Width}} width="{{.Width}}"{{end}}{{if .Height}} height="{{.Height}}"{{end}} frameborder="0" scrolling="no" sandbox="allow-scripts allow-popups allow-popups-to-escape-sandbox"></iframe>
+{{- end}}
+{{- range .Pixels}}
+<img src="{{.}}" width="1" height="1" alt="" style="display:none">
+{{- end}}
+</div>
+{{end}}
+{{- define "script" -}}
+<script src="{{.}}"></script>
+{{- end}}`))
+
+// Renderer renders companions, non-linear ads and icons as HTML snippets to
+// be inserted in a web page.
+//
+// Static image resources are rendered as images linked to the click-through
+// URI of the element. Flash and other static resources are rejected. IFrame
+// resources are rendered as iframes, while HTML resources and static
+// JavaScript resources are rendered as sandboxed iframes, so the ad markup
+// can't access the page. The creativeView trackers are rendered as hidden pixels.
+//
+// All values are escaped for their context and unsafe URIs, such as
+// javascript: ones, are neutralized.
+type Renderer struct {
+	// If not nil, the macros expanded in click-through and tracking URIs
+	Macros *Macros
+}
+
+// renderData is the input of renderTemplate
+type renderData struct {
+	Class         string
+	Width, Height int
+	AltText       string
+	ClickThrough  string
+	Image         string
+	IFrame        string
+	HTML          string
+	Pixels        []string
+}
+
+// resources holds the resources of a companion, non-linear or icon
+type resources struct {
+	static *StaticResource
+	iframe string
+	html   *HTMLResource
+}
+
+// Companion renders the companion ad as HTML.
+func (r *Renderer) Companion(c *Companion) (template.HTML, error) {
+	d := renderData{
+		Class:        "vast-companion",
+		Width:        c.Width,
+		Height:       c.Height,
+		AltText:      c.AltText,
+		ClickThrough: r.expand(c.CompanionClickThrough),
+		Pixels:       r.creativeView(c.TrackingEvents),
+	}
+	return r.render(d, resources{c.StaticResource, c.IFrameResource, c.HTMLResource})
+}
+
+// NonLinear renders the non-linear ad as HTML. Trackings are the tracking
+// events of the NonLinearAds element containing it.
+func (r *Renderer) NonLinear(nl *NonLinear, trackings []*Tracking) (template.HTML, error) {
+	d := renderData{
+		Class:        "vast-nonlinear",
+		Width:        nl.Width,
+		Height:       nl.Height,
+		ClickThrough: r.expand(nl.NonLinearClickThrough),
+		Pixels:       r.creativeView(trackings),
+	}
+	return r.render(d, resources{nl.StaticResource, nl.IFrameResource, nl.HTMLResource})
+}
+
+// Icon renders the icon as HTML. The IconViewTracking URIs are used as
+// creativeView trackers.
+func (r *Renderer) Icon(i *Icon) (template.HTML, error) {
+	d := renderData{
+		Class:        "vast-icon",
+		Width:        i.Width,
+		Height:       i.Height,
+		AltText:      i.Program,
+		ClickThrough: r.expand(i.IconClickThrough),
+	}
+	for _, uri := range i.IconViewTrackings {
+		if uri = r.expand(uri); uri != "" {
+			d.Pixels = append(d.Pixels, uri)
+		}
+	}
+	return r.render(d, resources{i.StaticResource, i.IFrameResource, i.HTMLResource})
+}
+
+// render renders the first supported resource of res, trying the static,
+// iframe and HTML resources in this order
+func (r *Renderer) render(d renderData, res resources) (template.HTML, error) {
+	err := fmt.Errorf("%w: no resource", ErrUnsupportedResource)
+	if res.static != nil && strings.TrimSpace(res.static.URI) != "" {
+		uri := strings.TrimSpace(res.static.URI)
+		switch t := strings.ToLower(strings.TrimSpace(res.static.CreativeType)); {
+		case strings.HasPrefix(t, "image/"):
+			d.Image = uri
+		case t == "application/javascript" || t == "application/x-javascript" || t == "text/javascript":
+			var b strings.Builder
+			if err := renderTemplate.ExecuteTemplate(&b, "script", uri); err != nil {
+				return "", err
+			}
+			d.HTML = b.String()
+		default:
+			err = fmt.Errorf("%w: static resource of type %q", ErrUnsupportedResource, res.static.CreativeType)
+		}
+	}
+	if d.Image == "" && d.HTML == "" {
+		switch {
+		case strings.TrimSpace(res.iframe) != "":
+			d.IFrame = strings.TrimSpace(res.iframe)
+		case res.html != nil && len(bytes.TrimSpace(res.html.HTML)) > 0:
+			// XML-encoded HTML has already been decoded by encoding/xml
+			d.HTML = strings.TrimSpace(string(res.html.HTML))
+		default:
+			return "", err
+		}
+	}
+	var b strings.Builder
+	if err := renderTemplate.ExecuteTemplate(&b, "element", d); err != nil {
+		return "", err
+	}
+	return template.HTML(b.String()), nil
+}
+
+// expand expands the macros of uri if the renderer has macros
+func (r *Renderer) expand(uri string) string {
+	if r.Macros == nil {
+		return strings.TrimSpace(uri)
+	}
+	return r.Macros.Expand(uri)
+}
+
+// creativeView returns the expanded URIs of the creativeView trackings
+func (r *Renderer) creativeView(trackings []*Tracking) []string {
+	var uris []string
+	for _, t := range trackings {
+		if t.TrackingEvent() == EventCreativeView && strings.TrimSpace(t.URI) != "" {
+			uris = append(uris, r.expand(t.URI))
+		}
+	}
+	return uris
+}
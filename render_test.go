@@ -0,0 +1,130 @@
+package vast
+
+import (
+	"encoding/xml"
+	"errors"
+	"flag"
+	"html/template"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of testdata/render")
+
+// assertGolden compares html with the content of testdata/render/name.html
+func assertGolden(t *testing.T, name string, html template.HTML) {
+	path := filepath.Join("testdata", "render", name+".html")
+	if *updateGolden {
+		if err := ioutil.WriteFile(path, []byte(html), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := ioutil.ReadFile(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, string(b), string(html), name)
+	}
+}
+
+func TestRenderCompanion(t *testing.T) {
+	v, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var m Macros
+	m.SetCacheBusting(42)
+	r := &Renderer{Macros: &m}
+	c := v.Ads[0].InLine.Creatives[1].CompanionAds.Companions[0]
+	c.AltText = `Blistex "lip" <care>`
+	c.TrackingEvents[0].URI += "?cb=[CACHEBUSTING]"
+	h, err := r.Companion(c)
+	if assert.NoError(t, err) {
+		assertGolden(t, "companion_static", h)
+	}
+
+	h, err = (&Renderer{}).Companion(&Companion{
+		Width:          300,
+		Height:         250,
+		IFrameResource: " http://example.com/companion.html ",
+	})
+	if assert.NoError(t, err) {
+		assertGolden(t, "companion_iframe", h)
+	}
+
+	var res HTMLResource
+	err = xml.Unmarshal([]byte(`<HTMLResource xmlEncoded="true">&lt;a href="http://example.com"&gt;Ad &amp;amp; more&lt;/a&gt;</HTMLResource>`), &res)
+	if !assert.NoError(t, err) {
+		return
+	}
+	h, err = (&Renderer{}).Companion(&Companion{
+		Width:          300,
+		Height:         250,
+		StaticResource: &StaticResource{CreativeType: "application/x-shockwave-flash", URI: "http://example.com/ad.swf"},
+		HTMLResource:   &res,
+	})
+	if assert.NoError(t, err) {
+		// The literal &amp; of the markup is kept
+		assertGolden(t, "companion_html", h)
+	}
+
+	h, err = (&Renderer{}).Companion(&Companion{
+		CompanionClickThrough: "javascript:alert(1)",
+		StaticResource:        &StaticResource{CreativeType: "image/png", URI: "http://example.com/ad.png"},
+	})
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(h), `<a href="#ZgotmplZ"`)
+	}
+
+	_, err = (&Renderer{}).Companion(&Companion{
+		StaticResource: &StaticResource{CreativeType: "application/x-shockwave-flash", URI: "http://example.com/ad.swf"},
+	})
+	assert.EqualError(t, err, `vast: unsupported resource: static resource of type "application/x-shockwave-flash"`)
+	assert.True(t, errors.Is(err, ErrUnsupportedResource))
+	_, err = (&Renderer{}).Companion(&Companion{})
+	assert.EqualError(t, err, "vast: unsupported resource: no resource")
+	assert.True(t, errors.Is(err, ErrUnsupportedResource))
+}
+
+func TestRenderNonLinear(t *testing.T) {
+	v, err := loadFixture("testdata/vast_inline_nonlinear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	nla := v.Ads[0].InLine.Creatives[0].NonLinearAds
+	h, err := (&Renderer{}).NonLinear(&nla.NonLinears[0], nla.TrackingEvents)
+	if assert.NoError(t, err) {
+		assertGolden(t, "nonlinear_static", h)
+	}
+
+	h, err = (&Renderer{}).NonLinear(&NonLinear{
+		Width:          480,
+		Height:         70,
+		StaticResource: &StaticResource{CreativeType: "application/javascript", URI: "http://example.com/overlay.js"},
+	}, nil)
+	if assert.NoError(t, err) {
+		assertGolden(t, "nonlinear_script", h)
+	}
+
+	h, err = (&Renderer{}).NonLinear(&NonLinear{
+		StaticResource: &StaticResource{CreativeType: "text/javascript", URI: `javascript:alert("x")`},
+	}, nil)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(h), `#ZgotmplZ`)
+	}
+}
+
+func TestRenderIcon(t *testing.T) {
+	h, err := (&Renderer{}).Icon(&Icon{
+		Program:           "AdChoices",
+		Width:             77,
+		Height:            15,
+		IconClickThrough:  "http://example.com/adchoices",
+		IconViewTrackings: []string{"http://example.com/icon/view"},
+		StaticResource:    &StaticResource{CreativeType: "image/png", URI: "http://example.com/adchoices.png"},
+	})
+	if assert.NoError(t, err) {
+		assertGolden(t, "icon_static", h)
+	}
+}
@@ -0,0 +1,211 @@
+package vast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxWrapperDepth is the number of wrappers followed by a Resolver with
+// no MaxDepth set. The IAB recommends players to accept at least 5 wrappers.
+const DefaultMaxWrapperDepth = 5
+
+var (
+	// ErrMaxWrapperDepth is returned when a wrapper chain is longer than the
+	// resolver's maximum depth.
+	ErrMaxWrapperDepth = errors.New("vast: wrapper limit reached")
+	// ErrWrapperCycle is returned when a wrapper points back to an ad tag
+	// already visited in the same chain.
+	ErrWrapperCycle = errors.New("vast: wrapper cycle detected")
+	// ErrNoAd is returned when a wrapped ad tag returns a VAST document
+	// without any ad.
+	ErrNoAd = errors.New("vast: no ad in wrapped response")
+	// ErrAdditionalWrapper is returned when a wrapped ad tag returns a wrapper
+	// while the followAdditionalWrappers attribute of the wrapper is false.
+	ErrAdditionalWrapper = errors.New("vast: additional wrapper not allowed")
+)
+
+// WrapperError is the error of a wrapper which could not be resolved. It
+// wraps ErrMaxWrapperDepth, ErrWrapperCycle, ErrNoAd, ErrAdditionalWrapper
+// or the error which occurred while fetching the wrapped ad tag.
+type WrapperError struct {
+	// The VASTAdTagURI of the wrapper
+	URI string
+	Err error
+}
+
+// Error implements the error interface.
+func (e *WrapperError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Err, e.URI)
+}
+
+// Unwrap returns the underlying error, so errors.Is can be used to match the
+// sentinel errors.
+func (e *WrapperError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the VAST error code to report for the error.
+func (e *WrapperError) Code() ErrorCode {
+	switch e.Err {
+	case ErrMaxWrapperDepth:
+		return ErrorWrapperLimit
+	case ErrNoAd:
+		return ErrorWrapperNoAd
+	}
+	if err, ok := e.Err.(interface{ Timeout() bool }); ok && err.Timeout() {
+		return ErrorWrapperTimeout
+	}
+	return ErrorWrapper
+}
+
+// Resolver follows the VASTAdTagURI of Wrapper ads until an InLine ad is found.
+//
+// The VAST 4 attributes of wrappers are honored: when followAdditionalWrappers
+// is false, a wrapped response containing a wrapper fails with
+// ErrAdditionalWrapper. When allowMultipleAds is false, only the first
+// stand-alone ad of the wrapped response is kept. When fallbackOnNoAd is false
+// and the wrapped response has no ad, the other stand-alone ads of the
+// document containing the wrapper are dropped. Wrappers without these
+// attributes are followed as in VAST 3.
+//
+// DAAST wrappers and wrapped DAAST responses are followed as well.
+type Resolver struct {
+	// Transport is used to fetch wrapped ad tags. If nil, http.DefaultTransport
+	// is used. Redirects are followed.
+	Transport http.RoundTripper
+	// MaxDepth is the maximum number of wrappers followed to reach an InLine
+	// ad. If zero, DefaultMaxWrapperDepth is used.
+	MaxDepth int
+}
+
+// ResolvedAd is an InLine ad along with the wrappers which led to it.
+type ResolvedAd struct {
+	// The ad containing the InLine element, or the wrapper which failed when
+	// Err is not nil
+	Ad *Ad
+	// The wrapper ads followed to reach the InLine ad, outermost first
+	Wrappers []*Ad
+	// The *WrapperError which prevented the wrapper Ad from being resolved
+	Err error
+}
+
+// Resolve follows all wrappers found in v and returns the InLine ads they lead
+// to. InLine ads found directly in v are returned with an empty wrapper chain.
+//
+// A wrapper which can't be resolved doesn't prevent the other ads from being
+// resolved: it is returned with its error set in ResolvedAd.Err. An error is
+// only returned when ctx is done.
+func (r *Resolver) Resolve(ctx context.Context, v *VAST) ([]*ResolvedAd, error) {
+	ads := r.resolve(ctx, v, nil, nil)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ads, nil
+}
+
+func (r *Resolver) resolve(ctx context.Context, v *VAST, chain []*Ad, visited []string) []*ResolvedAd {
+	fallback := true
+	results := make([][]*ResolvedAd, len(v.Ads))
+	for i, ad := range v.Ads {
+		switch {
+		case ad.InLine != nil:
+			results[i] = []*ResolvedAd{{Ad: ad, Wrappers: chain}}
+		case ad.Wrapper != nil:
+			res, err := r.follow(ctx, ad, chain, visited)
+			if err != nil {
+				res = []*ResolvedAd{{Ad: ad, Wrappers: chain, Err: err}}
+				if errors.Is(err, ErrNoAd) && ad.Wrapper.FallbackOnNoAd != nil && !*ad.Wrapper.FallbackOnNoAd {
+					fallback = false
+				}
+			}
+			results[i] = res
+		}
+	}
+	var ads []*ResolvedAd
+	for i, res := range results {
+		failed := len(res) == 1 && res[0].Ad == v.Ads[i] && res[0].Err != nil
+		if !fallback && !failed && v.Ads[i].Sequence == 0 {
+			continue
+		}
+		ads = append(ads, res...)
+	}
+	return ads
+}
+
+// follow fetches the ad tag of the wrapper ad and resolves the ads it returns
+func (r *Resolver) follow(ctx context.Context, ad *Ad, chain []*Ad, visited []string) ([]*ResolvedAd, error) {
+	maxDepth := r.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultMaxWrapperDepth
+	}
+	uri := strings.TrimSpace(ad.Wrapper.VASTAdTagURI)
+	if len(chain) >= maxDepth {
+		return nil, &WrapperError{URI: uri, Err: ErrMaxWrapperDepth}
+	}
+	for _, u := range visited {
+		if u == uri {
+			return nil, &WrapperError{URI: uri, Err: ErrWrapperCycle}
+		}
+	}
+	v, err := r.fetch(ctx, uri)
+	if err != nil {
+		return nil, &WrapperError{URI: uri, Err: err}
+	}
+	if a := ad.Wrapper.AllowMultipleAds; a != nil && !*a {
+		v.Ads = firstStandAlone(v.Ads)
+	}
+	if len(v.Ads) == 0 {
+		return nil, &WrapperError{URI: uri, Err: ErrNoAd}
+	}
+	if f := ad.Wrapper.FollowAdditionalWrappers; f != nil && !*f {
+		for _, wad := range v.Ads {
+			if wad.Wrapper != nil {
+				return nil, &WrapperError{URI: uri, Err: ErrAdditionalWrapper}
+			}
+		}
+	}
+	// Copy chain and visited so sibling ads don't share backing arrays
+	c := make([]*Ad, len(chain), len(chain)+1)
+	copy(c, chain)
+	vis := make([]string, len(visited), len(visited)+1)
+	copy(vis, visited)
+	return r.resolve(ctx, v, append(c, ad), append(vis, uri)), nil
+}
+
+// firstStandAlone returns the first ad without a sequence, if any
+func firstStandAlone(ads []*Ad) []*Ad {
+	for _, ad := range ads {
+		if ad.Sequence == 0 {
+			return []*Ad{ad}
+		}
+	}
+	return nil
+}
+
+// fetch requests the wrapped ad tag and decodes it as a VAST or DAAST document
+func (r *Resolver) fetch(ctx context.Context, uri string) (*VAST, error) {
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	t := r.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	res, err := (&http.Client{Transport: t}).Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vast: unexpected status %d", res.StatusCode)
+	}
+	v, err := decodeDocument(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("vast: cannot parse wrapped response: %v", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,260 @@
+package vast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rewriteTransport sends every request to the test server, keeping the path
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newFixtureServer() (*httptest.Server, *Resolver) {
+	mux := http.NewServeMux()
+	mux.Handle("/proddev/vast/", http.StripPrefix("/proddev/vast/", http.FileServer(http.Dir("testdata"))))
+	// /chain/n serves a wrapper pointing to /chain/n-1 and /chain/0 an inline ad
+	mux.HandleFunc("/chain/", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/chain/"), "%d", &n)
+		if n == 0 {
+			http.ServeFile(w, r, "testdata/vast_inline_linear.xml")
+			return
+		}
+		fmt.Fprintf(w, `<VAST version="3.0"><Ad><Wrapper><VASTAdTagURI>http://example.com/chain/%d</VASTAdTagURI></Wrapper></Ad></VAST>`, n-1)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<VAST version="3.0"><Ad><Wrapper><VASTAdTagURI>http://example.com/loop</VASTAdTagURI></Wrapper></Ad></VAST>`)
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<VAST version="3.0"></VAST>`)
+	})
+	mux.HandleFunc("/multiple", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<VAST version="4.0"><Ad id="p1" sequence="1"><InLine></InLine></Ad><Ad id="b1"><InLine></InLine></Ad><Ad id="b2"><InLine></InLine></Ad></VAST>`)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/proddev/vast/vast_inline_linear.xml", http.StatusFound)
+	})
+	mux.HandleFunc("/daast_inline_audio.xml", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/daast_inline_audio.xml")
+	})
+	ts := httptest.NewServer(mux)
+	u, _ := url.Parse(ts.URL)
+	return ts, &Resolver{Transport: rewriteTransport{target: u}}
+}
+
+func wrapperTo(uri string) *VAST {
+	return &VAST{Version: "3.0", Ads: []*Ad{{ID: "w", Wrapper: &Wrapper{VASTAdTagURI: uri}}}}
+}
+
+func TestResolveWrapper(t *testing.T) {
+	ts, r := newFixtureServer()
+	defer ts.Close()
+
+	v, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ads, err := r.Resolve(context.Background(), v)
+	if assert.NoError(t, err) && assert.Len(t, ads, 1) {
+		assert.Equal(t, "601364", ads[0].Ad.ID)
+		assert.NotNil(t, ads[0].Ad.InLine)
+		if assert.Len(t, ads[0].Wrappers, 1) {
+			assert.Equal(t, v.Ads[0], ads[0].Wrappers[0])
+		}
+	}
+}
+
+func TestResolveInLine(t *testing.T) {
+	ts, r := newFixtureServer()
+	defer ts.Close()
+
+	v, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ads, err := r.Resolve(context.Background(), v)
+	if assert.NoError(t, err) && assert.Len(t, ads, 1) {
+		assert.Equal(t, v.Ads[0], ads[0].Ad)
+		assert.Len(t, ads[0].Wrappers, 0)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	ts, r := newFixtureServer()
+	defer ts.Close()
+	ctx := context.Background()
+
+	ads, err := r.Resolve(ctx, wrapperTo("http://example.com/chain/4"))
+	if assert.NoError(t, err) && assert.Len(t, ads, 1) {
+		assert.Len(t, ads[0].Wrappers, 5)
+	}
+	ads, err = r.Resolve(ctx, wrapperTo("http://example.com/chain/5"))
+	if assert.NoError(t, err) && assert.Len(t, ads, 1) {
+		assert.True(t, errors.Is(ads[0].Err, ErrMaxWrapperDepth))
+		assert.Equal(t, ErrorWrapperLimit, ads[0].Err.(*WrapperError).Code())
+		assert.Len(t, ads[0].Wrappers, 5)
+		assert.NotNil(t, ads[0].Ad.Wrapper)
+		assert.Nil(t, ads[0].Flatten())
+	}
+
+	r.MaxDepth = 1
+	ads, _ = r.Resolve(ctx, wrapperTo("http://example.com/chain/1"))
+	if assert.Len(t, ads, 1) {
+		assert.True(t, errors.Is(ads[0].Err, ErrMaxWrapperDepth))
+	}
+	r.MaxDepth = 0
+
+	ads, _ = r.Resolve(ctx, wrapperTo("http://example.com/loop"))
+	if assert.Len(t, ads, 1) {
+		assert.True(t, errors.Is(ads[0].Err, ErrWrapperCycle))
+		assert.EqualError(t, ads[0].Err, "vast: wrapper cycle detected: http://example.com/loop")
+	}
+
+	ads, _ = r.Resolve(ctx, wrapperTo("http://example.com/empty"))
+	if assert.Len(t, ads, 1) {
+		assert.True(t, errors.Is(ads[0].Err, ErrNoAd))
+		assert.Equal(t, ErrorWrapperNoAd, ads[0].Err.(*WrapperError).Code())
+		assert.EqualError(t, ads[0].Err, "vast: no ad in wrapped response: http://example.com/empty")
+	}
+
+	v, err := loadFixture("testdata/vast_wrapper_nonlinear_1.xml")
+	if assert.NoError(t, err) {
+		ads, _ = r.Resolve(ctx, v)
+		if assert.Len(t, ads, 1) {
+			assert.EqualError(t, ads[0].Err, "vast: unexpected status 404: http://demo.tremormedia.com/proddev/vast/vast_inline_nonlinear2.xml")
+			assert.Equal(t, ErrorWrapper, ads[0].Err.(*WrapperError).Code())
+		}
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = r.Resolve(cctx, wrapperTo("http://example.com/chain/1"))
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestResolvePerAdErrors(t *testing.T) {
+	ts, r := newFixtureServer()
+	defer ts.Close()
+
+	v := &VAST{Version: "3.0", Ads: []*Ad{
+		{ID: "w1", Wrapper: &Wrapper{VASTAdTagURI: "http://example.com/empty"}},
+		{ID: "w2", Wrapper: &Wrapper{VASTAdTagURI: "http://example.com/chain/0"}},
+	}}
+	ads, err := r.Resolve(context.Background(), v)
+	if assert.NoError(t, err) && assert.Len(t, ads, 2) {
+		assert.Equal(t, "w1", ads[0].Ad.ID)
+		assert.True(t, errors.Is(ads[0].Err, ErrNoAd))
+		assert.Equal(t, "601364", ads[1].Ad.ID)
+		assert.NoError(t, ads[1].Err)
+	}
+}
+
+func TestResolveWrapperAttributes(t *testing.T) {
+	ts, r := newFixtureServer()
+	defer ts.Close()
+	ctx := context.Background()
+	no, yes := false, true
+
+	// followAdditionalWrappers
+	v := wrapperTo("http://example.com/chain/2")
+	v.Ads[0].Wrapper.FollowAdditionalWrappers = &no
+	ads, _ := r.Resolve(ctx, v)
+	if assert.Len(t, ads, 1) {
+		assert.True(t, errors.Is(ads[0].Err, ErrAdditionalWrapper))
+	}
+	v.Ads[0].Wrapper.VASTAdTagURI = "http://example.com/chain/0"
+	ads, _ = r.Resolve(ctx, v)
+	if assert.Len(t, ads, 1) {
+		assert.NoError(t, ads[0].Err)
+	}
+
+	// allowMultipleAds
+	v = wrapperTo("http://example.com/multiple")
+	ads, _ = r.Resolve(ctx, v)
+	assert.Equal(t, []string{"p1", "b1", "b2"}, resolvedIDs(ads))
+	v.Ads[0].Wrapper.AllowMultipleAds = &no
+	ads, _ = r.Resolve(ctx, v)
+	assert.Equal(t, []string{"b1"}, resolvedIDs(ads))
+	v.Ads[0].Wrapper.AllowMultipleAds = &yes
+	ads, _ = r.Resolve(ctx, v)
+	assert.Equal(t, []string{"p1", "b1", "b2"}, resolvedIDs(ads))
+
+	// fallbackOnNoAd
+	v = &VAST{Version: "4.0", Ads: []*Ad{
+		{ID: "p1", Sequence: 1, Wrapper: &Wrapper{VASTAdTagURI: "http://example.com/empty"}},
+		{ID: "p2", Sequence: 2, InLine: &InLine{}},
+		{ID: "b1", InLine: &InLine{}},
+	}}
+	ads, _ = r.Resolve(ctx, v)
+	assert.Equal(t, []string{"p1", "p2", "b1"}, resolvedIDs(ads))
+	v.Ads[0].Wrapper.FallbackOnNoAd = &no
+	ads, _ = r.Resolve(ctx, v)
+	assert.Equal(t, []string{"p1", "p2"}, resolvedIDs(ads))
+}
+
+func TestResolveDAAST(t *testing.T) {
+	ts, r := newFixtureServer()
+	defer ts.Close()
+
+	v, err := loadDAASTFixture("testdata/daast_wrapper.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ads, err := r.Resolve(context.Background(), v)
+	if assert.NoError(t, err) && assert.Len(t, ads, 1) {
+		assert.NoError(t, ads[0].Err)
+		assert.Equal(t, "audio", ads[0].Ad.AdType)
+		assert.NotNil(t, ads[0].Ad.InLine)
+	}
+}
+
+func TestResolveRedirect(t *testing.T) {
+	ts, r := newFixtureServer()
+	defer ts.Close()
+
+	ads, err := r.Resolve(context.Background(), wrapperTo("http://example.com/redirect"))
+	if assert.NoError(t, err) && assert.Len(t, ads, 1) {
+		assert.NoError(t, ads[0].Err)
+		assert.Equal(t, "601364", ads[0].Ad.ID)
+	}
+}
+
+func TestResolveVerifications(t *testing.T) {
+	ts, r := newFixtureServer()
+	defer ts.Close()
+
+	// The AdVerifications extension of the wrapped response is kept
+	v := wrapperTo("http://example.com/proddev/vast/vast_extensions.xml")
+	v.Ads[0].Wrapper.AdVerifications = []*Verification{{Vendor: "other.com-omid", JavaScriptResources: []*JavaScriptResource{{URI: "https://other.com/omid.js"}}}}
+	ads, err := r.Resolve(context.Background(), v)
+	if assert.NoError(t, err) && assert.Len(t, ads, 1) && assert.NoError(t, ads[0].Err) {
+		var vendors []string
+		for _, ver := range ads[0].Flatten().Verifications() {
+			vendors = append(vendors, ver.Vendor)
+		}
+		assert.Equal(t, []string{"company.com-omid", "other.com-omid"}, vendors)
+	}
+}
+
+func resolvedIDs(ads []*ResolvedAd) []string {
+	var ids []string
+	for _, ra := range ads {
+		ids = append(ids, ra.Ad.ID)
+	}
+	return ids
+}
@@ -0,0 +1,350 @@
+package vast
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrNoLinear is returned by NewSession when the ad has no linear creative.
+	ErrNoLinear = errors.New("vast: no linear creative")
+	// ErrInvalidEvent is returned by Session when a player event is not
+	// allowed in the current state of the session.
+	ErrInvalidEvent = errors.New("vast: invalid player event")
+	// ErrNotSkippable is returned by Session.Skip when the ad can't be skipped
+	// yet or at all.
+	ErrNotSkippable = errors.New("vast: ad is not skippable")
+)
+
+// EventError is returned by Session when a player event is not allowed in the
+// current state of the session. It wraps ErrInvalidEvent.
+type EventError struct {
+	// The reported event, e.g. "progress"
+	Event string
+	// The state of the session, e.g. "loaded"
+	State string
+}
+
+// Error implements the error interface.
+func (e *EventError) Error() string {
+	return fmt.Sprintf("%v: %s while %s", ErrInvalidEvent, e.Event, e.State)
+}
+
+// Unwrap returns ErrInvalidEvent.
+func (e *EventError) Unwrap() error {
+	return ErrInvalidEvent
+}
+
+// SkipError is returned by Session.Skip when the ad can't be skipped. It wraps
+// ErrNotSkippable.
+type SkipError struct {
+	// Whether the ad can be skipped later
+	Skippable bool
+	// The playhead from which the ad can be skipped, if Skippable
+	At time.Duration
+}
+
+// Error implements the error interface.
+func (e *SkipError) Error() string {
+	if !e.Skippable {
+		return ErrNotSkippable.Error()
+	}
+	return fmt.Sprintf("%v before %s", ErrNotSkippable, e.At)
+}
+
+// Unwrap returns ErrNotSkippable.
+func (e *SkipError) Unwrap() error {
+	return ErrNotSkippable
+}
+
+type sessionState int
+
+const (
+	stateIdle sessionState = iota
+	stateLoaded
+	statePlaying
+	statePaused
+	stateDone
+)
+
+func (s sessionState) String() string {
+	switch s {
+	case stateIdle:
+		return "idle"
+	case stateLoaded:
+		return "loaded"
+	case statePlaying:
+		return "playing"
+	case statePaused:
+		return "paused"
+	}
+	return "done"
+}
+
+// Session tracks the playback of a linear ad and tells which URIs must be
+// requested for each event reported by the player.
+//
+// The impressions, the creativeView, start, quartile, progress and complete
+// events, as well as the end of the playback (skip, close or error) are
+// reported once. The pause, resume, mute, unmute and fullscreen events are
+// reported each time the player state changes. Events not allowed in the
+// current state return an *EventError and fire nothing.
+//
+// A Session is not safe for concurrent use.
+type Session struct {
+	// The macros expanded in the returned URIs, may be nil. The [ADPLAYHEAD]
+	// macro is set by the session, as well as [PRICE] if it is not set and
+	// the ad has a numeric pricing.
+	Macros *Macros
+	// If not nil, the publisher policy overriding the skip offset of the ad
+	SkipPolicy *SkipPolicy
+
+	impressions []*Impression
+	errors      []string
+	pricing     *Pricing
+	linear      *Linear
+	cues        []Cue
+	fired       map[*Tracking]bool
+
+	state      sessionState
+	playhead   time.Duration
+	muted      bool
+	fullscreen bool
+}
+
+// NewSession starts a session for the first linear creative of the InLine ad.
+// To include the trackers of the wrappers, the ad returned by
+// ResolvedAd.Flatten should be used.
+func NewSession(ad *Ad) (*Session, error) {
+	if ad.InLine == nil {
+		return nil, ErrNoLinear
+	}
+	s := &Session{
+		impressions: ad.InLine.Impressions,
+		errors:      ad.InLine.Errors,
+		pricing:     ad.InLine.Pricing,
+		fired:       map[*Tracking]bool{},
+	}
+	for _, c := range ad.InLine.Creatives {
+		if c.Linear != nil {
+			s.linear = c.Linear
+			break
+		}
+	}
+	if s.linear == nil {
+		return nil, ErrNoLinear
+	}
+	for _, c := range s.linear.Timeline() {
+		// creativeView, start and complete are fired by Start and Complete
+		switch c.Tracking.TrackingEvent() {
+		case EventCreativeView, EventStart, EventComplete:
+		default:
+			s.cues = append(s.cues, c)
+		}
+	}
+	return s, nil
+}
+
+// Load reports the ad is loaded and ready to play, firing the loaded event.
+func (s *Session) Load() ([]string, error) {
+	if err := s.expect("load", stateIdle); err != nil {
+		return nil, err
+	}
+	s.state = stateLoaded
+	return s.fire(true, EventLoaded), nil
+}
+
+// Start reports the first frame of the ad is displayed, firing the
+// impressions, followed by the creativeView and start events.
+func (s *Session) Start() ([]string, error) {
+	if err := s.expect("start", stateIdle, stateLoaded); err != nil {
+		return nil, err
+	}
+	s.state = statePlaying
+	var uris []string
+	for _, imp := range s.impressions {
+		if imp.URI != "" {
+			uris = append(uris, s.expand(imp.URI))
+		}
+	}
+	uris = append(uris, s.fire(true, EventCreativeView, EventStart)...)
+	return append(uris, s.progress(0)...), nil
+}
+
+// Progress reports the playhead of the ad, firing the quartile and progress
+// events reached since the last call.
+func (s *Session) Progress(playhead time.Duration) ([]string, error) {
+	if err := s.expect("progress", statePlaying); err != nil {
+		return nil, err
+	}
+	return s.progress(playhead), nil
+}
+
+// Pause reports the ad was paused.
+func (s *Session) Pause() ([]string, error) {
+	if err := s.expect("pause", statePlaying); err != nil {
+		return nil, err
+	}
+	s.state = statePaused
+	return s.fire(false, EventPause), nil
+}
+
+// Resume reports the ad was resumed after a pause.
+func (s *Session) Resume() ([]string, error) {
+	if err := s.expect("resume", statePaused); err != nil {
+		return nil, err
+	}
+	s.state = statePlaying
+	return s.fire(false, EventResume), nil
+}
+
+// Mute reports the ad was muted. Nothing is fired if it was already muted.
+func (s *Session) Mute() ([]string, error) {
+	return s.toggle(EventMute, &s.muted, true)
+}
+
+// Unmute reports the ad was unmuted. Nothing is fired if it was not muted.
+func (s *Session) Unmute() ([]string, error) {
+	return s.toggle(EventUnmute, &s.muted, false)
+}
+
+// Fullscreen reports the player entered fullscreen, firing the fullscreen and
+// playerExpand events. Nothing is fired if it was already in fullscreen.
+func (s *Session) Fullscreen() ([]string, error) {
+	return s.toggle(EventFullscreen, &s.fullscreen, true, EventPlayerExpand)
+}
+
+// ExitFullscreen reports the player exited fullscreen, firing the
+// exitFullscreen and playerCollapse events.
+func (s *Session) ExitFullscreen() ([]string, error) {
+	return s.toggle(EventExitFullscreen, &s.fullscreen, false, EventPlayerCollapse)
+}
+
+// Complete reports the ad played until its end, firing the remaining
+// quartile and progress events followed by the complete event.
+func (s *Session) Complete() ([]string, error) {
+	if err := s.expect("complete", statePlaying); err != nil {
+		return nil, err
+	}
+	end := s.linear.duration()
+	if end < s.playhead {
+		end = s.playhead
+	}
+	uris := s.progress(end)
+	s.state = stateDone
+	return append(uris, s.fire(true, EventComplete)...), nil
+}
+
+// Skip reports the user skipped the ad at the given playhead. A *SkipError is
+// returned if the ad is not skippable, or not yet, as decided by the skip
+// offset of the ad and the SkipPolicy of the session.
+func (s *Session) Skip(playhead time.Duration) ([]string, error) {
+	if err := s.expect("skip", statePlaying, statePaused); err != nil {
+		return nil, err
+	}
+	var d SkipDecision
+	if s.SkipPolicy != nil {
+		d = s.SkipPolicy.Apply(s.linear)
+	} else {
+		d.At, d.Skippable = s.linear.SkipAt()
+	}
+	if !d.Skippable {
+		return nil, &SkipError{}
+	}
+	if !d.CanSkip(playhead) {
+		return nil, &SkipError{Skippable: true, At: d.At}
+	}
+	s.playhead = playhead
+	s.state = stateDone
+	return s.fire(true, EventSkip), nil
+}
+
+// Close reports the user closed the ad before its end, firing the close and
+// closeLinear events.
+func (s *Session) Close() ([]string, error) {
+	if err := s.expect("close", stateLoaded, statePlaying, statePaused); err != nil {
+		return nil, err
+	}
+	s.state = stateDone
+	return s.fire(true, EventClose, EventCloseLinear), nil
+}
+
+// Error reports the ad failed, returning the error URIs of the ad with the
+// [ERRORCODE] macro set to code.
+func (s *Session) Error(code ErrorCode) ([]string, error) {
+	if err := s.expect("error", stateIdle, stateLoaded, statePlaying, statePaused); err != nil {
+		return nil, err
+	}
+	s.state = stateDone
+	return expandErrorURIs(s.errors, code, s.macros()), nil
+}
+
+// expect returns an error if the session is not in one of the given states
+func (s *Session) expect(event string, states ...sessionState) error {
+	for _, st := range states {
+		if s.state == st {
+			return nil
+		}
+	}
+	return &EventError{Event: event, State: s.state.String()}
+}
+
+// toggle sets the flag to on, firing the event and its aliases if it changed
+func (s *Session) toggle(event TrackingEvent, flag *bool, on bool, aliases ...TrackingEvent) ([]string, error) {
+	if err := s.expect(string(event), stateLoaded, statePlaying, statePaused); err != nil {
+		return nil, err
+	}
+	if *flag == on {
+		return nil, nil
+	}
+	*flag = on
+	return s.fire(false, append([]TrackingEvent{event}, aliases...)...), nil
+}
+
+// progress moves the playhead, firing the cues reached and not fired yet
+func (s *Session) progress(playhead time.Duration) []string {
+	if playhead > s.playhead {
+		s.playhead = playhead
+	}
+	var uris []string
+	for _, c := range s.cues {
+		if c.Offset > playhead || s.fired[c.Tracking] {
+			continue
+		}
+		s.fired[c.Tracking] = true
+		uris = append(uris, s.expand(c.Tracking.URI))
+	}
+	return uris
+}
+
+// fire returns the URIs of the tracking events of the given types. When
+// once is true, trackings already fired are skipped.
+func (s *Session) fire(once bool, events ...TrackingEvent) []string {
+	var uris []string
+	for _, e := range events {
+		for _, t := range s.linear.TrackingEvents {
+			if t.TrackingEvent() != e || (once && s.fired[t]) {
+				continue
+			}
+			s.fired[t] = true
+			uris = append(uris, s.expand(t.URI))
+		}
+	}
+	return uris
+}
+
+// macros returns the macros of the session with the playhead set
+func (s *Session) macros() *Macros {
+	m := s.Macros.clone()
+	m.SetAdPlayhead(s.playhead)
+	if _, ok := m.Get(MacroPrice); !ok {
+		// Obfuscated prices leave the macro for the server to expand
+		_ = m.SetPrice(s.pricing)
+	}
+	return m
+}
+
+func (s *Session) expand(uri string) string {
+	return s.macros().Expand(uri)
+}
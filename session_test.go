@@ -0,0 +1,171 @@
+package vast
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSession(t *testing.T, skip *Offset) *Session {
+	b := NewInLine("1", "Acme", "Session").
+		Impression("http://t/impression").
+		Error("http://t/error?code=[ERRORCODE]").
+		LinearCreative(20 * time.Second).
+		MediaFile(&MediaFile{Delivery: "progressive", Type: "video/mp4", Width: 640, Height: 360, URI: "http://cdn/video.mp4"})
+	for _, e := range []TrackingEvent{"complete", "thirdQuartile", "midpoint", "firstQuartile", "start", "creativeView",
+		"loaded", "pause", "resume", "mute", "unmute", "fullscreen", "exitFullscreen", "skip", "close", "closeLinear"} {
+		b.Tracking(e, "http://t/"+string(e))
+	}
+	d := Duration(7 * time.Second)
+	b.ProgressTracking(Offset{Duration: &d}, "http://t/progress?p=[ADPLAYHEAD]")
+	if skip != nil {
+		b.SkipOffset(*skip)
+	}
+	v, err := b.Build()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	s, err := NewSession(v.Ads[0])
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return s
+}
+
+func TestSessionComplete(t *testing.T) {
+	s := newTestSession(t, nil)
+
+	uris, err := s.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://t/loaded"}, uris)
+	_, err = s.Progress(time.Second)
+	assert.EqualError(t, err, "vast: invalid player event: progress while loaded")
+
+	uris, err = s.Start()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://t/impression", "http://t/creativeView", "http://t/start"}, uris)
+
+	uris, _ = s.Progress(2 * time.Second)
+	assert.Len(t, uris, 0)
+	uris, _ = s.Progress(8 * time.Second)
+	assert.Equal(t, []string{"http://t/firstQuartile", "http://t/progress?p=00%3A00%3A08.000"}, uris)
+	uris, _ = s.Progress(8 * time.Second)
+	assert.Len(t, uris, 0)
+
+	uris, _ = s.Mute()
+	assert.Equal(t, []string{"http://t/mute"}, uris)
+	uris, _ = s.Mute()
+	assert.Len(t, uris, 0)
+	uris, _ = s.Fullscreen()
+	assert.Equal(t, []string{"http://t/fullscreen"}, uris)
+
+	uris, _ = s.Pause()
+	assert.Equal(t, []string{"http://t/pause"}, uris)
+	_, err = s.Pause()
+	assert.Error(t, err)
+	uris, _ = s.Resume()
+	assert.Equal(t, []string{"http://t/resume"}, uris)
+
+	uris, _ = s.Progress(11 * time.Second)
+	assert.Equal(t, []string{"http://t/midpoint"}, uris)
+	uris, err = s.Complete()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://t/thirdQuartile", "http://t/complete"}, uris)
+
+	_, err = s.Close()
+	assert.EqualError(t, err, "vast: invalid player event: close while done")
+	_, err = s.Error(ErrorUndefined)
+	assert.Error(t, err)
+}
+
+func TestSessionSkip(t *testing.T) {
+	s := newTestSession(t, nil)
+	s.Start()
+	_, err := s.Skip(10 * time.Second)
+	assert.True(t, errors.Is(err, ErrNotSkippable))
+	assert.EqualError(t, err, "vast: ad is not skippable")
+
+	d := Duration(5 * time.Second)
+	s = newTestSession(t, &Offset{Duration: &d})
+	_, err = s.Skip(time.Second)
+	assert.Equal(t, &EventError{Event: "skip", State: "idle"}, err)
+	assert.True(t, errors.Is(err, ErrInvalidEvent))
+	assert.EqualError(t, err, "vast: invalid player event: skip while idle")
+	uris, _ := s.Start()
+	assert.Equal(t, []string{"http://t/impression", "http://t/creativeView", "http://t/start"}, uris)
+	_, err = s.Skip(3 * time.Second)
+	assert.Equal(t, &SkipError{Skippable: true, At: 5 * time.Second}, err)
+	assert.True(t, errors.Is(err, ErrNotSkippable))
+	assert.EqualError(t, err, "vast: ad is not skippable before 5s")
+	s.Pause()
+	uris, err = s.Skip(6 * time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://t/skip"}, uris)
+	_, err = s.Complete()
+	assert.Error(t, err)
+}
+
+func TestSessionSkipPolicy(t *testing.T) {
+	s := newTestSession(t, nil)
+	s.SkipPolicy = &SkipPolicy{ForceAfter: 3 * time.Second}
+	s.Start()
+	_, err := s.Skip(2 * time.Second)
+	assert.EqualError(t, err, "vast: ad is not skippable before 3s")
+	uris, err := s.Skip(3 * time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://t/skip"}, uris)
+
+	d := Duration(5 * time.Second)
+	s = newTestSession(t, &Offset{Duration: &d})
+	s.SkipPolicy = &SkipPolicy{Never: true}
+	s.Start()
+	_, err = s.Skip(10 * time.Second)
+	assert.Equal(t, &SkipError{}, err)
+}
+
+func TestSessionCloseAndError(t *testing.T) {
+	s := newTestSession(t, nil)
+	s.Start()
+	uris, err := s.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://t/close", "http://t/closeLinear"}, uris)
+
+	s = newTestSession(t, nil)
+	var m Macros
+	m.BlankUnknown = true
+	s.Macros = &m
+	uris, err = s.Error(ErrorMediaFileNotFound)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://t/error?code=401"}, uris)
+
+	_, err = NewSession(&Ad{InLine: &InLine{Creatives: []*Creative{{NonLinearAds: &NonLinearAds{}}}}})
+	assert.Equal(t, ErrNoLinear, err)
+	_, err = NewSession(&Ad{Wrapper: &Wrapper{}})
+	assert.Equal(t, ErrNoLinear, err)
+}
+
+func TestSessionPrice(t *testing.T) {
+	ad := &Ad{InLine: &InLine{
+		Impressions: []*Impression{{URI: "http://t/impression?p=[PRICE]"}},
+		Pricing:     &Pricing{Model: "cpm", Currency: "USD", Value: " 2.50 "},
+		Creatives:   []*Creative{{Linear: &Linear{}}},
+	}}
+	s, err := NewSession(ad)
+	assert.NoError(t, err)
+	uris, err := s.Start()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://t/impression?p=2.5"}, uris)
+
+	var m Macros
+	m.Set(MacroPrice, "AhK3bFx9")
+	ad.InLine.Pricing.Value = "${AUCTION_PRICE}"
+	s, _ = NewSession(ad)
+	s.Macros = &m
+	uris, _ = s.Start()
+	assert.Equal(t, []string{"http://t/impression?p=AhK3bFx9"}, uris)
+	s, _ = NewSession(ad)
+	uris, _ = s.Start()
+	assert.Equal(t, []string{"http://t/impression?p=[PRICE]"}, uris)
+}
@@ -0,0 +1,70 @@
+package vast
+
+import (
+	"fmt"
+	"time"
+)
+
+// SkipAt returns the playhead from which the linear creative can be skipped.
+// False is returned if the creative is not skippable, or if its skip offset is
+// a percentage and its duration is unknown.
+func (l *Linear) SkipAt() (time.Duration, bool) {
+	if l.SkipOffset == nil {
+		return 0, false
+	}
+	if l.SkipOffset.Duration == nil && l.Duration == nil {
+		return 0, false
+	}
+	return l.SkipOffset.Resolve(l.duration()), true
+}
+
+// CanSkip tells if the linear creative can be skipped at the given playhead.
+func (l *Linear) CanSkip(playhead time.Duration) bool {
+	at, ok := l.SkipAt()
+	return ok && playhead >= at
+}
+
+// SkipPolicy is a publisher policy overriding the skip offset of creatives.
+type SkipPolicy struct {
+	// If true, ads are never skippable. Takes precedence over ForceAfter.
+	Never bool
+	// If not zero, ads are skippable after ForceAfter, or earlier if their
+	// skip offset allows it
+	ForceAfter time.Duration
+}
+
+// SkipDecision is the result of a SkipPolicy applied to a linear creative
+type SkipDecision struct {
+	// Whether the ad can be skipped
+	Skippable bool
+	// The playhead from which the ad can be skipped
+	At time.Duration
+	// Whether the policy changed the skip offset of the creative
+	Overridden bool
+	// A human readable description of the override
+	Reason string
+}
+
+// Apply returns when the linear creative can be skipped under the policy.
+func (p SkipPolicy) Apply(l *Linear) SkipDecision {
+	at, ok := l.SkipAt()
+	d := SkipDecision{Skippable: ok, At: at}
+	switch {
+	case p.Never && ok:
+		d = SkipDecision{Overridden: true, Reason: "policy forbids skipping"}
+	case p.Never:
+	case p.ForceAfter > 0 && (!ok || p.ForceAfter < at):
+		d = SkipDecision{Skippable: true, At: p.ForceAfter, Overridden: true}
+		if ok {
+			d.Reason = fmt.Sprintf("policy allows skipping after %s instead of %s", p.ForceAfter, at)
+		} else {
+			d.Reason = fmt.Sprintf("policy allows skipping non skippable ad after %s", p.ForceAfter)
+		}
+	}
+	return d
+}
+
+// CanSkip tells if the ad can be skipped at the given playhead.
+func (d SkipDecision) CanSkip(playhead time.Duration) bool {
+	return d.Skippable && playhead >= d.At
+}
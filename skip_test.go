@@ -0,0 +1,77 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func skipLinear(dur time.Duration, skip *Offset) *Linear {
+	l := &Linear{SkipOffset: skip}
+	if dur > 0 {
+		d := Duration(dur)
+		l.Duration = &d
+	}
+	return l
+}
+
+func durationOffset(d time.Duration) *Offset {
+	dd := Duration(d)
+	return &Offset{Duration: &dd}
+}
+
+func TestLinearSkipAt(t *testing.T) {
+	l := skipLinear(30*time.Second, durationOffset(5*time.Second))
+	at, ok := l.SkipAt()
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, at)
+	assert.False(t, l.CanSkip(4*time.Second))
+	assert.True(t, l.CanSkip(5*time.Second))
+
+	l = skipLinear(30*time.Second, &Offset{Percent: .25})
+	at, ok = l.SkipAt()
+	assert.True(t, ok)
+	assert.Equal(t, 7500*time.Millisecond, at)
+	assert.False(t, l.CanSkip(7*time.Second))
+	assert.True(t, l.CanSkip(8*time.Second))
+
+	// Percent offsets can't be resolved without the duration
+	l = skipLinear(0, &Offset{Percent: .25})
+	_, ok = l.SkipAt()
+	assert.False(t, ok)
+	assert.False(t, l.CanSkip(time.Hour))
+
+	l = skipLinear(30*time.Second, nil)
+	_, ok = l.SkipAt()
+	assert.False(t, ok)
+	assert.False(t, l.CanSkip(time.Hour))
+}
+
+func TestSkipPolicy(t *testing.T) {
+	skippable := skipLinear(30*time.Second, durationOffset(10*time.Second))
+	notSkippable := skipLinear(30*time.Second, nil)
+
+	d := SkipPolicy{}.Apply(skippable)
+	assert.Equal(t, SkipDecision{Skippable: true, At: 10 * time.Second}, d)
+
+	d = SkipPolicy{ForceAfter: 5 * time.Second}.Apply(skippable)
+	assert.Equal(t, SkipDecision{Skippable: true, At: 5 * time.Second, Overridden: true,
+		Reason: "policy allows skipping after 5s instead of 10s"}, d)
+	assert.True(t, d.CanSkip(5*time.Second))
+
+	d = SkipPolicy{ForceAfter: 15 * time.Second}.Apply(skippable)
+	assert.Equal(t, SkipDecision{Skippable: true, At: 10 * time.Second}, d)
+
+	d = SkipPolicy{ForceAfter: 5 * time.Second}.Apply(notSkippable)
+	assert.True(t, d.Overridden)
+	assert.Equal(t, "policy allows skipping non skippable ad after 5s", d.Reason)
+	assert.False(t, d.CanSkip(4*time.Second))
+
+	d = SkipPolicy{Never: true, ForceAfter: 5 * time.Second}.Apply(skippable)
+	assert.Equal(t, SkipDecision{Overridden: true, Reason: "policy forbids skipping"}, d)
+	assert.False(t, d.CanSkip(time.Hour))
+
+	d = SkipPolicy{Never: true}.Apply(notSkippable)
+	assert.Equal(t, SkipDecision{}, d)
+}
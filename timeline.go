@@ -0,0 +1,63 @@
+package vast
+
+import (
+	"sort"
+	"time"
+)
+
+// quartiles gives the position of time based events as a fraction of the
+// linear creative duration
+var quartiles = map[TrackingEvent]float64{
+	EventCreativeView:  0,
+	EventStart:         0,
+	EventFirstQuartile: .25,
+	EventMidpoint:      .5,
+	EventThirdQuartile: .75,
+	EventComplete:      1,
+}
+
+// Cue is a tracking event to be fired when the playhead reaches Offset
+type Cue struct {
+	Offset   time.Duration
+	Tracking *Tracking
+}
+
+// Timeline returns the time based tracking events of the linear creative
+// (creativeView, start, quartiles, complete and progress) as cue points sorted
+// by ascending offset.
+//
+// Events relative to the creative duration, i.e. quartiles, complete and
+// percent based progress events, are omitted when the duration is unknown.
+// Events triggered by the user, like pause or mute, are never part of the timeline.
+func (l *Linear) Timeline() []Cue {
+	var cues []Cue
+	for _, t := range l.TrackingEvents {
+		var offset time.Duration
+		e := t.TrackingEvent()
+		if e == EventProgress {
+			if t.Offset == nil || (t.Offset.Duration == nil && l.Duration == nil) {
+				continue
+			}
+			offset = t.Offset.Resolve(l.duration())
+		} else {
+			q, found := quartiles[e]
+			if !found || (q > 0 && l.Duration == nil) {
+				continue
+			}
+			offset = time.Duration(q * float64(l.duration())).Round(time.Millisecond)
+		}
+		cues = append(cues, Cue{Offset: offset, Tracking: t})
+	}
+	sort.SliceStable(cues, func(i, j int) bool {
+		return cues[i].Offset < cues[j].Offset
+	})
+	return cues
+}
+
+// duration returns the duration of the linear creative or 0 if unknown
+func (l *Linear) duration() time.Duration {
+	if l.Duration == nil {
+		return 0
+	}
+	return time.Duration(*l.Duration)
+}
@@ -0,0 +1,54 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearTimeline(t *testing.T) {
+	v, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	linear := v.Ads[0].InLine.Creatives[0].Linear
+	ten := Duration(10 * time.Second)
+	linear.TrackingEvents = append(linear.TrackingEvents,
+		&Tracking{Event: "progress", Offset: &Offset{Duration: &ten}, URI: "http://t/progress-10s"},
+		&Tracking{Event: "progress", Offset: &Offset{Percent: .1}, URI: "http://t/progress-10%"},
+		&Tracking{Event: "progress", URI: "http://t/progress-no-offset"},
+		&Tracking{Event: "pause", URI: "http://t/pause"},
+	)
+
+	type cue struct {
+		offset time.Duration
+		event  string
+		uri    string
+	}
+	var cues []cue
+	for _, c := range linear.Timeline() {
+		cues = append(cues, cue{c.Offset, c.Tracking.Event, c.Tracking.URI})
+	}
+	assert.Equal(t, []cue{
+		{0, "creativeView", "http://myTrackingURL/creativeView"},
+		{0, "start", "http://myTrackingURL/start"},
+		{3 * time.Second, "progress", "http://t/progress-10%"},
+		{7500 * time.Millisecond, "firstQuartile", "http://myTrackingURL/firstQuartile"},
+		{10 * time.Second, "progress", "http://t/progress-10s"},
+		{15 * time.Second, "midpoint", "http://myTrackingURL/midpoint"},
+		{22500 * time.Millisecond, "thirdQuartile", "http://myTrackingURL/thirdQuartile"},
+		{30 * time.Second, "complete", "http://myTrackingURL/complete"},
+	}, cues)
+
+	linear.Duration = nil
+	cues = nil
+	for _, c := range linear.Timeline() {
+		cues = append(cues, cue{c.Offset, c.Tracking.Event, c.Tracking.URI})
+	}
+	assert.Equal(t, []cue{
+		{0, "creativeView", "http://myTrackingURL/creativeView"},
+		{0, "start", "http://myTrackingURL/start"},
+		{10 * time.Second, "progress", "http://t/progress-10s"},
+	}, cues)
+}
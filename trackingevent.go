@@ -0,0 +1,175 @@
+package vast
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownTrackingEvent is returned by ParseTrackingEvent when the name is
+// not a tracking event defined by the VAST spec.
+var ErrUnknownTrackingEvent = errors.New("vast: unknown tracking event")
+
+// TrackingEvent is the name of a tracking event, as found in the event
+// attribute of a Tracking element.
+type TrackingEvent string
+
+// Tracking events defined by VAST 2.0 to 4.1.
+const (
+	EventCreativeView            TrackingEvent = "creativeView"
+	EventStart                   TrackingEvent = "start"
+	EventFirstQuartile           TrackingEvent = "firstQuartile"
+	EventMidpoint                TrackingEvent = "midpoint"
+	EventThirdQuartile           TrackingEvent = "thirdQuartile"
+	EventComplete                TrackingEvent = "complete"
+	EventMute                    TrackingEvent = "mute"
+	EventUnmute                  TrackingEvent = "unmute"
+	EventPause                   TrackingEvent = "pause"
+	EventRewind                  TrackingEvent = "rewind"
+	EventResume                  TrackingEvent = "resume"
+	EventFullscreen              TrackingEvent = "fullscreen"
+	EventExitFullscreen          TrackingEvent = "exitFullscreen"
+	EventExpand                  TrackingEvent = "expand"
+	EventCollapse                TrackingEvent = "collapse"
+	EventAcceptInvitation        TrackingEvent = "acceptInvitation"
+	EventClose                   TrackingEvent = "close"
+	EventProgress                TrackingEvent = "progress"
+	EventSkip                    TrackingEvent = "skip"
+	EventCloseLinear             TrackingEvent = "closeLinear"
+	EventAcceptInvitationLinear  TrackingEvent = "acceptInvitationLinear"
+	EventPlayerExpand            TrackingEvent = "playerExpand"
+	EventPlayerCollapse          TrackingEvent = "playerCollapse"
+	EventNotUsed                 TrackingEvent = "notUsed"
+	EventLoaded                  TrackingEvent = "loaded"
+	EventInteractiveStart        TrackingEvent = "interactiveStart"
+	EventOtherAdInteraction      TrackingEvent = "otherAdInteraction"
+	EventAdExpand                TrackingEvent = "adExpand"
+	EventAdCollapse              TrackingEvent = "adCollapse"
+	EventMinimize                TrackingEvent = "minimize"
+	EventOverlayViewDuration     TrackingEvent = "overlayViewDuration"
+	EventVerificationNotExecuted TrackingEvent = "verificationNotExecuted"
+)
+
+// TrackingElement is the element holding a Tracking
+type TrackingElement string
+
+// Elements holding tracking events.
+const (
+	TrackingLinear       TrackingElement = "Linear"
+	TrackingNonLinearAds TrackingElement = "NonLinearAds"
+	TrackingCompanion    TrackingElement = "Companion"
+	TrackingVerification TrackingElement = "Verification"
+)
+
+// trackingEventSpec tells where and since when an event can be used
+type trackingEventSpec struct {
+	elems []TrackingElement
+	// The first version defining the event
+	since string
+	// The last version defining the event, empty if still defined
+	until string
+}
+
+var (
+	linearOnly  = []TrackingElement{TrackingLinear}
+	nonLinear   = []TrackingElement{TrackingNonLinearAds}
+	videoEvents = []TrackingElement{TrackingLinear, TrackingNonLinearAds}
+	allElements = []TrackingElement{TrackingLinear, TrackingNonLinearAds, TrackingCompanion}
+)
+
+var trackingEvents = map[TrackingEvent]trackingEventSpec{
+	EventCreativeView:            {allElements, "2.0", ""},
+	EventStart:                   {linearOnly, "2.0", ""},
+	EventFirstQuartile:           {linearOnly, "2.0", ""},
+	EventMidpoint:                {linearOnly, "2.0", ""},
+	EventThirdQuartile:           {linearOnly, "2.0", ""},
+	EventComplete:                {linearOnly, "2.0", ""},
+	EventMute:                    {videoEvents, "2.0", ""},
+	EventUnmute:                  {videoEvents, "2.0", ""},
+	EventPause:                   {videoEvents, "2.0", ""},
+	EventRewind:                  {videoEvents, "2.0", ""},
+	EventResume:                  {videoEvents, "2.0", ""},
+	EventFullscreen:              {videoEvents, "2.0", "3.0"},
+	EventExitFullscreen:          {videoEvents, "3.0", "3.0"},
+	EventExpand:                  {videoEvents, "2.0", "4.0"},
+	EventCollapse:                {videoEvents, "2.0", "4.0"},
+	EventAcceptInvitation:        {videoEvents, "2.0", ""},
+	EventClose:                   {videoEvents, "2.0", ""},
+	EventProgress:                {linearOnly, "3.0", ""},
+	EventSkip:                    {linearOnly, "3.0", ""},
+	EventCloseLinear:             {linearOnly, "3.0", ""},
+	EventAcceptInvitationLinear:  {linearOnly, "3.0", ""},
+	EventPlayerExpand:            {videoEvents, "4.0", ""},
+	EventPlayerCollapse:          {videoEvents, "4.0", ""},
+	EventNotUsed:                 {allElements, "4.0", ""},
+	EventLoaded:                  {linearOnly, "4.0", ""},
+	EventInteractiveStart:        {linearOnly, "4.1", ""},
+	EventOtherAdInteraction:      {videoEvents, "4.1", ""},
+	EventAdExpand:                {nonLinear, "4.1", ""},
+	EventAdCollapse:              {nonLinear, "4.1", ""},
+	EventMinimize:                {nonLinear, "4.1", ""},
+	EventOverlayViewDuration:     {nonLinear, "4.1", ""},
+	EventVerificationNotExecuted: {[]TrackingElement{TrackingVerification}, "4.1", ""},
+}
+
+// lowerTrackingEvents indexes the events by their lower case name
+var lowerTrackingEvents = func() map[string]TrackingEvent {
+	m := make(map[string]TrackingEvent, len(trackingEvents))
+	for e := range trackingEvents {
+		m[strings.ToLower(string(e))] = e
+	}
+	return m
+}()
+
+// ParseTrackingEvent returns the tracking event named s. The name is matched
+// leniently, ignoring the case and surrounding spaces, and normalized tells
+// if s differs from the canonical name of the event, e.g. for "firstquartile".
+func ParseTrackingEvent(s string) (e TrackingEvent, normalized bool, err error) {
+	if _, found := trackingEvents[TrackingEvent(s)]; found {
+		return TrackingEvent(s), false, nil
+	}
+	e, found := lowerTrackingEvents[strings.ToLower(strings.TrimSpace(s))]
+	if !found {
+		return "", false, fmt.Errorf("%w: %q", ErrUnknownTrackingEvent, s)
+	}
+	return e, true, nil
+}
+
+// Known tells if the event is defined by the VAST spec.
+func (e TrackingEvent) Known() bool {
+	_, found := trackingEvents[e]
+	return found
+}
+
+// Versions returns the first and last versions of the spec defining the
+// event. The last version is empty if the event is defined by the latest
+// versions.
+func (e TrackingEvent) Versions() (since, until string) {
+	s := trackingEvents[e]
+	return s.since, s.until
+}
+
+// ValidFor tells if the event can be used in the tracking events of the
+// element in the given version of the spec.
+func (e TrackingEvent) ValidFor(elem TrackingElement, version string) bool {
+	s, found := trackingEvents[e]
+	if !found || compareVersions(version, s.since) < 0 || (s.until != "" && compareVersions(version, s.until) > 0) {
+		return false
+	}
+	for _, el := range s.elems {
+		if el == elem {
+			return true
+		}
+	}
+	return false
+}
+
+// TrackingEvent returns the event of the tracking, normalized by
+// ParseTrackingEvent. Unknown events are returned as is.
+func (t *Tracking) TrackingEvent() TrackingEvent {
+	e, _, err := ParseTrackingEvent(t.Event)
+	if err != nil {
+		return TrackingEvent(t.Event)
+	}
+	return e
+}
@@ -0,0 +1,98 @@
+package vast
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrackingEvent(t *testing.T) {
+	e, normalized, err := ParseTrackingEvent("firstQuartile")
+	assert.NoError(t, err)
+	assert.Equal(t, EventFirstQuartile, e)
+	assert.False(t, normalized)
+
+	e, normalized, err = ParseTrackingEvent(" firstquartile ")
+	assert.NoError(t, err)
+	assert.Equal(t, EventFirstQuartile, e)
+	assert.True(t, normalized)
+
+	e, normalized, err = ParseTrackingEvent("CLOSELINEAR")
+	assert.NoError(t, err)
+	assert.Equal(t, EventCloseLinear, e)
+	assert.True(t, normalized)
+
+	_, _, err = ParseTrackingEvent("firstQuartil")
+	assert.EqualError(t, err, `vast: unknown tracking event: "firstQuartil"`)
+	assert.True(t, errors.Is(err, ErrUnknownTrackingEvent))
+	_, _, err = ParseTrackingEvent("")
+	assert.Error(t, err)
+}
+
+func TestTrackingEventValidFor(t *testing.T) {
+	assert.True(t, EventCreativeView.ValidFor(TrackingCompanion, "2.0"))
+	assert.True(t, EventStart.ValidFor(TrackingLinear, "4.1"))
+	assert.False(t, EventStart.ValidFor(TrackingCompanion, "4.1"))
+	assert.False(t, EventSkip.ValidFor(TrackingLinear, "2.0"))
+	assert.True(t, EventSkip.ValidFor(TrackingLinear, "3.0"))
+	assert.False(t, EventFullscreen.ValidFor(TrackingLinear, "4.0"))
+	assert.True(t, EventPlayerExpand.ValidFor(TrackingNonLinearAds, "4.0"))
+	assert.False(t, EventAdExpand.ValidFor(TrackingNonLinearAds, "4.0"))
+	assert.True(t, EventAdExpand.ValidFor(TrackingNonLinearAds, "4.2"))
+	assert.True(t, EventVerificationNotExecuted.ValidFor(TrackingVerification, "4.1"))
+	assert.False(t, TrackingEvent("firstquartile").ValidFor(TrackingLinear, "4.1"))
+
+	since, until := EventExitFullscreen.Versions()
+	assert.Equal(t, "3.0", since)
+	assert.Equal(t, "3.0", until)
+	assert.True(t, EventLoaded.Known())
+	assert.False(t, TrackingEvent("breakStart").Known())
+}
+
+func TestTrackingEventLenientMatching(t *testing.T) {
+	d := Duration(40 * time.Second)
+	l := &Linear{Duration: &d, TrackingEvents: []*Tracking{
+		{Event: "FirstQuartile", URI: "http://t/q1"},
+		{Event: "midpoint", URI: "http://t/mid"},
+		{Event: "unknown", URI: "http://t/unknown"},
+	}}
+	var uris []string
+	for _, c := range l.Timeline() {
+		uris = append(uris, c.Tracking.URI)
+	}
+	assert.Equal(t, []string{"http://t/q1", "http://t/mid"}, uris)
+	assert.Equal(t, EventFirstQuartile, l.TrackingEvents[0].TrackingEvent())
+	assert.Equal(t, TrackingEvent("unknown"), l.TrackingEvents[2].TrackingEvent())
+}
+
+func TestValidateTrackingEvents(t *testing.T) {
+	d := Duration(0)
+	v := &VAST{
+		Version: "3.0",
+		Ads: []*Ad{
+			{InLine: &InLine{
+				AdSystem:    &AdSystem{Name: "foo"},
+				AdTitle:     "bar",
+				Impressions: []*Impression{{URI: "http://t"}},
+				Creatives: []*Creative{
+					{Linear: &Linear{
+						Duration:   &d,
+						MediaFiles: []*MediaFile{{URI: "http://t", Delivery: "progressive", Type: "video/mp4", Width: 1, Height: 1}},
+						TrackingEvents: []*Tracking{
+							{Event: "firstquartile", URI: "http://t"},
+							{Event: "loaded", URI: "http://t"},
+							{Event: "firstQuartil", URI: "http://t"},
+						},
+					}},
+				},
+			}},
+		},
+	}
+	assert.Equal(t, []Violation{
+		{Path: "Ads[0].InLine.Creatives[0].Linear.TrackingEvents[0]", Severity: SeverityWarning, Ref: "VAST 3.0 Linear/Tracking@event", Message: `event "firstquartile" should be spelled "firstQuartile"`},
+		{Path: "Ads[0].InLine.Creatives[0].Linear.TrackingEvents[1]", Severity: SeverityWarning, Ref: "VAST 3.0 Linear/Tracking@event", Message: `event "loaded" is not defined for Linear in VAST 3.0`},
+		{Path: "Ads[0].InLine.Creatives[0].Linear.TrackingEvents[2]", Severity: SeverityError, Ref: "VAST 3.0 Linear/Tracking@event", Message: `unknown event "firstQuartil"`},
+	}, v.Validate(""))
+}
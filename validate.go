@@ -0,0 +1,315 @@
+package vast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Severity tells how serious a Violation is
+type Severity int
+
+const (
+	// SeverityWarning is used for violations players commonly cope with
+	SeverityWarning Severity = iota
+	// SeverityError is used for violations of required elements or attributes
+	SeverityError
+)
+
+// String implements the fmt.Stringer interface.
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Violation describes a part of a VAST document not complying with the spec
+type Violation struct {
+	// Location of the offending element, e.g. Ads[0].InLine.Creatives[1].Linear
+	Path string `json:"path"`
+	// How serious the violation is
+	Severity Severity `json:"severity"`
+	// The spec version and element the rule comes from, e.g. "VAST 3.0 InLine/Impression"
+	Ref string `json:"ref"`
+	// Human readable description of the violation
+	Message string `json:"message"`
+}
+
+// String implements the fmt.Stringer interface.
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", v.Severity, v.Path, v.Message, v.Ref)
+}
+
+// daastVersions maps the DAAST versions to the VAST version they are based
+// on. VAST 1.0 documents can't be decoded in the VAST model, so a 1.0 version
+// denotes a document decoded by DecodeDAAST.
+var daastVersions = map[string]string{
+	"1.0": "3.0",
+}
+
+// Validate checks the document against the given version of the VAST spec
+// and returns all the violations found. If version is empty, the version of
+// the document is used. DAAST 1.0 documents are validated against VAST 3.0.
+func (v *VAST) Validate(version string) []Violation {
+	if version == "" {
+		version = v.Version
+	}
+	vd := &validator{spec: "VAST " + version, version: version}
+	if vv, found := daastVersions[version]; found {
+		vd.spec = "DAAST " + version
+		vd.version = vv
+	}
+	vd.validateVAST(v)
+	return vd.violations
+}
+
+type validator struct {
+	// The spec and version used in the Ref of violations
+	spec string
+	// The VAST version the rules are checked against
+	version string
+	// Whether the version is supported, enabling the per-version rules
+	supported  bool
+	violations []Violation
+}
+
+func (vd *validator) add(sev Severity, path, elem, format string, args ...interface{}) {
+	vd.violations = append(vd.violations, Violation{
+		Path:     path,
+		Severity: sev,
+		Ref:      vd.spec + " " + elem,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// atLeast tells if the validated version is greater or equal to min
+func (vd *validator) atLeast(min string) bool {
+	return compareVersions(vd.version, min) >= 0
+}
+
+// compareVersions compares two major.minor versions
+func compareVersions(a, b string) int {
+	am, an := splitVersion(a)
+	bm, bn := splitVersion(b)
+	switch {
+	case am != bm:
+		return am - bm
+	default:
+		return an - bn
+	}
+}
+
+func splitVersion(v string) (major, minor int) {
+	parts := strings.SplitN(v, ".", 3)
+	major, _ = strconv.Atoi(parts[0])
+	if len(parts) > 1 {
+		minor, _ = strconv.Atoi(parts[1])
+	}
+	return major, minor
+}
+
+func (vd *validator) validateVAST(v *VAST) {
+	switch vd.version {
+	case "2.0", "3.0", "4.0", "4.1", "4.2", "4.3":
+		vd.supported = true
+	case "":
+		vd.add(SeverityError, "VAST", "VAST@version", "missing version")
+	default:
+		vd.add(SeverityError, "VAST", "VAST@version", "unsupported version %q", vd.version)
+	}
+	for i, ad := range v.Ads {
+		vd.validateAd(ad, fmt.Sprintf("Ads[%d]", i))
+	}
+}
+
+func (vd *validator) validateAd(ad *Ad, path string) {
+	switch {
+	case ad.InLine != nil && ad.Wrapper != nil:
+		vd.add(SeverityError, path, "Ad", "ad contains both InLine and Wrapper")
+	case ad.InLine == nil && ad.Wrapper == nil:
+		vd.add(SeverityError, path, "Ad", "ad contains neither InLine nor Wrapper")
+	}
+	switch ad.AdType {
+	case "", "video", "audio", "hybrid":
+	default:
+		vd.add(SeverityError, path, "Ad@adType", "invalid adType %q", ad.AdType)
+	}
+	if ad.Sequence < 0 || ad.zeroSequence {
+		vd.add(SeverityError, path, "Ad@sequence", "sequence must be greater than zero")
+	}
+	if ad.InLine != nil {
+		vd.validateInLine(ad.InLine, path+".InLine")
+	}
+	if ad.Wrapper != nil {
+		vd.validateWrapper(ad.Wrapper, path+".Wrapper")
+	}
+}
+
+func (vd *validator) validateInLine(in *InLine, path string) {
+	if in.AdSystem == nil || strings.TrimSpace(in.AdSystem.Name) == "" {
+		vd.add(SeverityError, path, "InLine/AdSystem", "missing AdSystem")
+	}
+	if strings.TrimSpace(in.AdTitle) == "" {
+		vd.add(SeverityError, path, "InLine/AdTitle", "missing AdTitle")
+	}
+	if len(in.Impressions) == 0 {
+		vd.add(SeverityError, path, "InLine/Impression", "missing Impression")
+	}
+	if vd.atLeast("4.1") && strings.TrimSpace(in.AdServingID) == "" {
+		vd.add(SeverityError, path, "InLine/AdServingId", "missing AdServingId")
+	}
+	if len(in.Creatives) == 0 {
+		vd.add(SeverityError, path, "InLine/Creatives", "missing Creatives")
+	}
+	for i, c := range in.Creatives {
+		vd.validateCreative(c, fmt.Sprintf("%s.Creatives[%d]", path, i))
+	}
+}
+
+func (vd *validator) validateWrapper(w *Wrapper, path string) {
+	if w.AdSystem == nil || strings.TrimSpace(w.AdSystem.Name) == "" {
+		vd.add(SeverityError, path, "Wrapper/AdSystem", "missing AdSystem")
+	}
+	if strings.TrimSpace(w.VASTAdTagURI) == "" {
+		vd.add(SeverityError, path, "Wrapper/VASTAdTagURI", "missing VASTAdTagURI")
+	}
+	if len(w.Impressions) == 0 {
+		vd.add(SeverityError, path, "Wrapper/Impression", "missing Impression")
+	}
+	for i, c := range w.Creatives {
+		p := fmt.Sprintf("%s.Creatives[%d]", path, i)
+		if c.Linear != nil {
+			vd.validateTrackings(c.Linear.TrackingEvents, p+".Linear", "Linear")
+		}
+		if c.NonLinearAds != nil {
+			vd.validateTrackings(c.NonLinearAds.TrackingEvents, p+".NonLinearAds", "NonLinearAds")
+		}
+	}
+}
+
+func (vd *validator) validateCreative(c *Creative, path string) {
+	n := 0
+	if c.Linear != nil {
+		n++
+		vd.validateLinear(c.Linear, path+".Linear")
+	}
+	if c.NonLinearAds != nil {
+		n++
+		vd.validateNonLinearAds(c.NonLinearAds, path+".NonLinearAds")
+	}
+	if c.CompanionAds != nil {
+		n++
+		vd.validateCompanionAds(c.CompanionAds, path+".CompanionAds")
+	}
+	if n != 1 {
+		vd.add(SeverityError, path, "Creative", "creative must contain exactly one of Linear, NonLinearAds or CompanionAds, found %d", n)
+	}
+	if vd.atLeast("4.0") && len(c.UniversalAdIDs) == 0 {
+		vd.add(SeverityError, path, "Creative/UniversalAdId", "missing UniversalAdId")
+	}
+}
+
+func (vd *validator) validateLinear(l *Linear, path string) {
+	if l.Duration == nil {
+		vd.add(SeverityError, path, "Linear/Duration", "missing Duration")
+	}
+	if l.SkipOffset != nil && !vd.atLeast("3.0") {
+		vd.add(SeverityWarning, path, "Linear@skipoffset", "skipoffset requires VAST 3.0")
+	}
+	if len(l.MediaFiles) == 0 {
+		vd.add(SeverityError, path, "Linear/MediaFiles", "missing MediaFile")
+	}
+	for i, mf := range l.MediaFiles {
+		vd.validateMediaFile(mf, fmt.Sprintf("%s.MediaFiles[%d]", path, i))
+	}
+	vd.validateTrackings(l.TrackingEvents, path, "Linear")
+}
+
+func (vd *validator) validateMediaFile(mf *MediaFile, path string) {
+	switch mf.Delivery {
+	case "progressive", "streaming":
+	case "":
+		vd.add(SeverityError, path, "MediaFile@delivery", "missing delivery")
+	default:
+		vd.add(SeverityError, path, "MediaFile@delivery", "invalid delivery %q", mf.Delivery)
+	}
+	if mf.Type == "" {
+		vd.add(SeverityError, path, "MediaFile@type", "missing type")
+	}
+	// Audio files have no pixel dimensions
+	if mf.Width <= 0 && !mf.IsAudio() {
+		vd.add(SeverityError, path, "MediaFile@width", "missing width")
+	}
+	if mf.Height <= 0 && !mf.IsAudio() {
+		vd.add(SeverityError, path, "MediaFile@height", "missing height")
+	}
+	if strings.TrimSpace(mf.URI) == "" {
+		vd.add(SeverityError, path, "MediaFile", "missing URI")
+	}
+	if mf.Bitrate > 0 && (mf.MinBitrate > 0 || mf.MaxBitrate > 0) {
+		vd.add(SeverityWarning, path, "MediaFile@bitrate", "bitrate should not be used along with minBitrate and maxBitrate")
+	}
+	if (mf.MinBitrate > 0) != (mf.MaxBitrate > 0) {
+		vd.add(SeverityWarning, path, "MediaFile@minBitrate", "minBitrate and maxBitrate must be provided together")
+	}
+}
+
+func (vd *validator) validateNonLinearAds(nla *NonLinearAds, path string) {
+	for i, nl := range nla.NonLinears {
+		p := fmt.Sprintf("%s.NonLinears[%d]", path, i)
+		if nl.StaticResource == nil && nl.IFrameResource == "" && nl.HTMLResource == nil {
+			vd.add(SeverityError, p, "NonLinear", "missing resource")
+		}
+	}
+	vd.validateTrackings(nla.TrackingEvents, path, "NonLinearAds")
+}
+
+func (vd *validator) validateCompanionAds(ca *CompanionAds, path string) {
+	switch ca.Required {
+	case "", "all", "any", "none":
+	default:
+		vd.add(SeverityError, path, "CompanionAds@required", "invalid required value %q", ca.Required)
+	}
+	for i, c := range ca.Companions {
+		p := fmt.Sprintf("%s.Companions[%d]", path, i)
+		if c.Width <= 0 || c.Height <= 0 {
+			vd.add(SeverityError, p, "Companion@width", "missing width or height")
+		}
+		if c.StaticResource == nil && c.IFrameResource == "" && c.HTMLResource == nil {
+			vd.add(SeverityError, p, "Companion", "missing resource")
+		}
+		vd.validateTrackings(c.TrackingEvents, p, "Companion")
+	}
+}
+
+func (vd *validator) validateTrackings(trackings []*Tracking, path, elem string) {
+	for i, t := range trackings {
+		p := fmt.Sprintf("%s.TrackingEvents[%d]", path, i)
+		if t.Event == "" {
+			vd.add(SeverityError, p, elem+"/Tracking@event", "missing event")
+		} else {
+			vd.validateTrackingEvent(t.Event, p, elem)
+		}
+		if t.TrackingEvent() == EventProgress && t.Offset == nil {
+			vd.add(SeverityError, p, elem+"/Tracking@offset", "progress event requires an offset")
+		}
+		if strings.TrimSpace(t.URI) == "" {
+			vd.add(SeverityWarning, p, elem+"/Tracking", "missing URI")
+		}
+	}
+}
+
+func (vd *validator) validateTrackingEvent(event, path, elem string) {
+	e, normalized, err := ParseTrackingEvent(event)
+	switch {
+	case err != nil:
+		vd.add(SeverityError, path, elem+"/Tracking@event", "unknown event %q", event)
+		return
+	case normalized:
+		vd.add(SeverityWarning, path, elem+"/Tracking@event", "event %q should be spelled %q", event, e)
+	}
+	if vd.supported && !e.ValidFor(TrackingElement(elem), vd.version) {
+		vd.add(SeverityWarning, path, elem+"/Tracking@event", "event %q is not defined for %s in %s", e, elem, vd.spec)
+	}
+}
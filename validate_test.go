@@ -0,0 +1,98 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFixtures(t *testing.T) {
+	for _, path := range []string{
+		"testdata/vast_inline_linear.xml",
+		"testdata/vast_inline_nonlinear.xml",
+		"testdata/vast_wrapper_linear_1.xml",
+		"testdata/vast_wrapper_nonlinear_1.xml",
+	} {
+		v, err := loadFixture(path)
+		if assert.NoError(t, err) {
+			assert.Len(t, v.Validate(""), 0, path)
+		}
+	}
+
+	v, err := loadFixture("testdata/vast4_inline_linear.xml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Violation{{
+			Path:     "Ads[0].InLine.Creatives[0].Linear.MediaFiles[0]",
+			Severity: SeverityWarning,
+			Ref:      "VAST 4.1 MediaFile@bitrate",
+			Message:  "bitrate should not be used along with minBitrate and maxBitrate",
+		}}, v.Validate(""))
+	}
+}
+
+func TestValidateSequence(t *testing.T) {
+	var v VAST
+	err := xml.Unmarshal([]byte(`<VAST version="3.0"><Ad id="a" sequence="0"></Ad><Ad id="b" sequence="-1"></Ad><Ad id="c"></Ad></VAST>`), &v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var paths []string
+	for _, violation := range v.Validate("") {
+		if violation.Ref == "VAST 3.0 Ad@sequence" {
+			paths = append(paths, violation.Path)
+		}
+	}
+	assert.Equal(t, []string{"Ads[0]", "Ads[1]"}, paths)
+}
+
+func TestValidateViolations(t *testing.T) {
+	d := Duration(0)
+	v := &VAST{
+		Version: "3.0",
+		Ads: []*Ad{
+			{InLine: &InLine{}, Wrapper: &Wrapper{AdSystem: &AdSystem{Name: "foo"}, VASTAdTagURI: "http://t", Impressions: []*Impression{{URI: "http://t"}}}},
+			{InLine: &InLine{
+				AdSystem:    &AdSystem{Name: "foo"},
+				AdTitle:     "bar",
+				Impressions: []*Impression{{URI: "http://t"}},
+				Creatives: []*Creative{
+					{Linear: &Linear{Duration: &d, MediaFiles: []*MediaFile{{URI: "http://t", Delivery: "download"}}}},
+					{Linear: &Linear{TrackingEvents: []*Tracking{{Event: "progress", URI: "http://t"}}}},
+				},
+			}},
+		},
+	}
+	assert.Equal(t, []Violation{
+		{Path: "Ads[0]", Severity: SeverityError, Ref: "VAST 3.0 Ad", Message: "ad contains both InLine and Wrapper"},
+		{Path: "Ads[0].InLine", Severity: SeverityError, Ref: "VAST 3.0 InLine/AdSystem", Message: "missing AdSystem"},
+		{Path: "Ads[0].InLine", Severity: SeverityError, Ref: "VAST 3.0 InLine/AdTitle", Message: "missing AdTitle"},
+		{Path: "Ads[0].InLine", Severity: SeverityError, Ref: "VAST 3.0 InLine/Impression", Message: "missing Impression"},
+		{Path: "Ads[0].InLine", Severity: SeverityError, Ref: "VAST 3.0 InLine/Creatives", Message: "missing Creatives"},
+		{Path: "Ads[1].InLine.Creatives[0].Linear.MediaFiles[0]", Severity: SeverityError, Ref: "VAST 3.0 MediaFile@delivery", Message: `invalid delivery "download"`},
+		{Path: "Ads[1].InLine.Creatives[0].Linear.MediaFiles[0]", Severity: SeverityError, Ref: "VAST 3.0 MediaFile@type", Message: "missing type"},
+		{Path: "Ads[1].InLine.Creatives[0].Linear.MediaFiles[0]", Severity: SeverityError, Ref: "VAST 3.0 MediaFile@width", Message: "missing width"},
+		{Path: "Ads[1].InLine.Creatives[0].Linear.MediaFiles[0]", Severity: SeverityError, Ref: "VAST 3.0 MediaFile@height", Message: "missing height"},
+		{Path: "Ads[1].InLine.Creatives[1].Linear", Severity: SeverityError, Ref: "VAST 3.0 Linear/Duration", Message: "missing Duration"},
+		{Path: "Ads[1].InLine.Creatives[1].Linear", Severity: SeverityError, Ref: "VAST 3.0 Linear/MediaFiles", Message: "missing MediaFile"},
+		{Path: "Ads[1].InLine.Creatives[1].Linear.TrackingEvents[0]", Severity: SeverityError, Ref: "VAST 3.0 Linear/Tracking@offset", Message: "progress event requires an offset"},
+	}, v.Validate(""))
+}
+
+func TestValidateVersion(t *testing.T) {
+	v, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	violations := v.Validate("4.1")
+	if assert.Len(t, violations, 3) {
+		assert.Equal(t, "error: Ads[0].InLine: missing AdServingId (VAST 4.1 InLine/AdServingId)", violations[0].String())
+		assert.Equal(t, "Ads[0].InLine.Creatives[0]", violations[1].Path)
+		assert.Equal(t, "VAST 4.1 Creative/UniversalAdId", violations[1].Ref)
+	}
+
+	v.Version = "1.1"
+	if violations := v.Validate(""); assert.Len(t, violations, 1) {
+		assert.Equal(t, `unsupported version "1.1"`, violations[0].Message)
+	}
+}
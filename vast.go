@@ -1,9 +1,13 @@
 // Package vast implements IAB VAST 3.0 specification http://www.iab.net/media/file/VASTv3.0.pdf
+// along with the elements introduced by VAST 4.0 to 4.3 https://iabtechlab.com/standards/vast/
 package vast
 
+import "encoding/xml"
+
 // VAST is the root <VAST> tag
 type VAST struct {
-	// The version of the VAST spec (should be either "2.0" or "3.0")
+	// The version of the VAST spec (should be either "2.0", "3.0", "4.0",
+	// "4.1", "4.2" or "4.3")
 	Version string `xml:"version,attr" json:"version,omitempty"`
 	// One or more Ad elements. Advertisers and video content publishers may
 	// associate an <Ad> element with a line item video ad defined in contract
@@ -25,9 +29,15 @@ type Ad struct {
 	// A number greater than zero (0) that identifies the sequence in which
 	// an ad should play; all <Ad> elements with sequence values are part of
 	// a pod and are intended to be played in sequence
-	Sequence int      `xml:"sequence,attr,omitempty" json:"sequence,omitempty"`
-	InLine   *InLine  `xml:",omitempty" json:"inline,omitempty"`
-	Wrapper  *Wrapper `xml:",omitempty" json:"wrapper,omitempty"`
+	Sequence int `xml:"sequence,attr,omitempty" json:"sequence,omitempty"`
+	// The type of the ad, either "video", "audio" or "hybrid" (VAST 4.1).
+	// Video is assumed when empty.
+	AdType  string   `xml:"adType,attr,omitempty" json:"ad_type,omitempty"`
+	InLine  *InLine  `xml:",omitempty" json:"inline,omitempty"`
+	Wrapper *Wrapper `xml:",omitempty" json:"wrapper,omitempty"`
+
+	// Set when the document has an explicit sequence="0"
+	zeroSequence bool
 }
 
 // InLine is a vast <InLine> ad element containing actual ad definition
@@ -40,6 +50,9 @@ type InLine struct {
 	AdSystem *AdSystem `json:"ad_system,omitempty"`
 	// The common name of the ad
 	AdTitle string `json:"ad_title,omitempty"`
+	// An identifier used to compare impression-level data across systems
+	// (VAST 4.1)
+	AdServingID string `xml:"AdServingId,omitempty" json:"ad_serving_id,omitempty"`
 	// One or more URIs that directs the video player to a tracking resource file that the
 	// video player should request when the first frame of the ad is displayed
 	Impressions []*Impression `xml:"Impression" json:"impressions,omitempty"`
@@ -47,6 +60,9 @@ type InLine struct {
 	Creatives []*Creative `xml:"Creatives>Creative" json:"creatives,omitempty"`
 	// A string value that provides a longer description of the ad.
 	Description string `xml:",omitempty" json:"description,omitempty"`
+	// The categories of the ad content, as codes of a given authority
+	// (VAST 4.0)
+	Categories []*Category `xml:"Category,omitempty" json:"categories,omitempty"`
 	// The name of the advertiser as defined by the ad serving party.
 	// This element can be used to prevent displaying ads with advertiser
 	// competitors. Ad serving parties and publishers should identify how
@@ -66,7 +82,14 @@ type InLine struct {
 	// Provides a value that represents a price that can be used by real-time bidding
 	// (RTB) systems. VAST is not designed to handle RTB since other methods exist,
 	// but this element is offered for custom solutions if needed.
-	Pricing string `xml:",omitempty" json:"pricing,omitempty"`
+	Pricing *Pricing `xml:",omitempty" json:"pricing,omitempty"`
+	// The number of seconds in which the ad is valid for execution (VAST 4.1)
+	Expires int `xml:",omitempty" json:"expires,omitempty"`
+	// URIs to ping when the ad is determined to be viewable, not viewable or
+	// when viewability cannot be determined (VAST 4.0)
+	ViewableImpression *ViewableImpression `xml:",omitempty" json:"viewable_impression,omitempty"`
+	// The resources needed by verification vendors to measure the ad (VAST 4.1)
+	AdVerifications []*Verification `xml:"AdVerifications>Verification,omitempty" json:"ad_verifications,omitempty"`
 	// XML node for custom extensions, as defined by the ad server. When used, a
 	// custom element should be nested under <Extensions> to help separate custom
 	// XML elements from VAST elements. The following example includes a custom
@@ -74,6 +97,67 @@ type InLine struct {
 	Extensions *Extensions `xml:",omitempty" json:"extensions,omitempty"`
 }
 
+// Category is a code identifying the ad content category as defined by an
+// authority such as the IAB Content Taxonomy.
+type Category struct {
+	// A URL for the organization that defined the category codes
+	Authority string `xml:"authority,attr,omitempty" json:"authority,omitempty"`
+	Code      string `xml:",chardata" json:"code,omitempty"`
+}
+
+// BlockedAdCategories is a list of ad categories the publisher doesn't want
+// in the wrapped ad.
+type BlockedAdCategories struct {
+	// A URL for the organization that defined the category codes
+	Authority string `xml:"authority,attr,omitempty" json:"authority,omitempty"`
+	// A comma separated list of category codes
+	Categories string `xml:",chardata" json:"categories,omitempty"`
+}
+
+// ViewableImpression contains URIs to ping depending on the viewability of the ad
+type ViewableImpression struct {
+	ID string `xml:"id,attr,omitempty" json:"id,omitempty"`
+	// URIs to ping when the ad meets the viewability criteria
+	Viewable []string `xml:",omitempty" json:"viewable,omitempty"`
+	// URIs to ping when the ad played but did not meet the viewability criteria
+	NotViewable []string `xml:",omitempty" json:"not_viewable,omitempty"`
+	// URIs to ping when viewability could not be determined
+	ViewUndetermined []string `xml:",omitempty" json:"view_undetermined,omitempty"`
+}
+
+// Verification contains the resources and metadata required to execute
+// third-party measurement code in order to verify creative playback.
+type Verification struct {
+	// An identifier for the verification vendor
+	Vendor string `xml:"vendor,attr,omitempty" json:"vendor,omitempty"`
+	// The JavaScript resources used to collect verification data
+	JavaScriptResources []*JavaScriptResource `xml:"JavaScriptResource,omitempty" json:"javascript_resources,omitempty"`
+	// The executable resources used to collect verification data
+	ExecutableResources []*ExecutableResource `xml:"ExecutableResource,omitempty" json:"executable_resources,omitempty"`
+	// The verification vendor's trackers, such as verificationNotExecuted
+	TrackingEvents []*Tracking `xml:"TrackingEvents>Tracking,omitempty" json:"tracking_events,omitempty"`
+	// Parameters to pass to the verification resource
+	VerificationParameters string `xml:",omitempty" json:"verification_parameters,omitempty"`
+}
+
+// JavaScriptResource is the URL to a JavaScript file used for verification
+type JavaScriptResource struct {
+	// The API framework used to execute the resource (e.g. "omid")
+	APIFramework string `xml:"apiFramework,attr,omitempty" json:"api_framework,omitempty"`
+	// Whether the resource can be executed outside of a browser environment
+	BrowserOptional bool   `xml:"browserOptional,attr,omitempty" json:"browser_optional,omitempty"`
+	URI             string `xml:",chardata" json:"url,omitempty"`
+}
+
+// ExecutableResource is the URL to a non JavaScript file used for verification
+type ExecutableResource struct {
+	// The API framework used to execute the resource
+	APIFramework string `xml:"apiFramework,attr,omitempty" json:"api_framework,omitempty"`
+	// The type of executable resource provided
+	Type string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	URI  string `xml:",chardata" json:"url,omitempty"`
+}
+
 // Impression is a URI that directs the video player to a tracking resource file that
 // the video player should request when the first frame of the ad is displayed
 type Impression struct {
@@ -104,6 +188,15 @@ type Pricing struct {
 // the ad supply chain must contain all the necessary files needed to display
 // the ad.
 type Wrapper struct {
+	// Whether a wrapped response without any ad should make the player
+	// fall back on other ads of the VAST document (VAST 4.0)
+	FallbackOnNoAd *bool `xml:"fallbackOnNoAd,attr,omitempty" json:"fallback_on_no_ad,omitempty"`
+	// Whether the wrapped response is allowed to return multiple ads. Default
+	// is false (VAST 4.0)
+	AllowMultipleAds *bool `xml:"allowMultipleAds,attr,omitempty" json:"allow_multiple_ads,omitempty"`
+	// Whether the wrapped response is allowed to be another wrapper. Default
+	// is true (VAST 4.0)
+	FollowAdditionalWrappers *bool `xml:"followAdditionalWrappers,attr,omitempty" json:"follow_additional_wrappers,omitempty"`
 	// The name of the ad server that returned the ad
 	AdSystem *AdSystem `json:"ad_system,omitempty"`
 	// URL of ad tag of downstream Secondary Ad Server
@@ -116,6 +209,12 @@ type Wrapper struct {
 	Errors []string `xml:"Error,omitempty" json:"errors,omitempty"`
 	// The container for one or more <Creative> elements
 	Creatives []*CreativeWrapper `xml:"Creatives>Creative" json:"creatives,omitempty"`
+	// URIs to ping depending on the viewability of the ad (VAST 4.0)
+	ViewableImpression *ViewableImpression `xml:",omitempty" json:"viewable_impression,omitempty"`
+	// The resources needed by verification vendors to measure the ad (VAST 4.1)
+	AdVerifications []*Verification `xml:"AdVerifications>Verification,omitempty" json:"ad_verifications,omitempty"`
+	// Ad categories the wrapped ad must not belong to (VAST 4.1)
+	BlockedAdCategories []*BlockedAdCategories `xml:",omitempty" json:"blocked_ad_categories,omitempty"`
 	// XML node for custom extensions, as defined by the ad server. When used, a
 	// custom element should be nested under <Extensions> to help separate custom
 	// XML elements from VAST elements. The following example includes a custom
@@ -139,6 +238,9 @@ type Creative struct {
 	AdID string `xml:"AdID,attr,omitempty" json:"adid,omitempty"`
 	// The technology used for any included API
 	APIFramework string `xml:"apiFramework,attr,omitempty" json:"api_framework,omitempty"`
+	// Unique creative identifiers maintained across systems, one per registry
+	// (VAST 4.0, several allowed since 4.1)
+	UniversalAdIDs []*UniversalAdID `xml:"UniversalAdId,omitempty" json:"universal_ad_ids,omitempty"`
 	// If present, defines a linear creative
 	Linear *Linear `xml:",omitempty" json:"linear,omitempty"`
 	// If defined, defins companions creatives
@@ -147,6 +249,15 @@ type Creative struct {
 	NonLinearAds *NonLinearAds `xml:",omitempty" json:"nonlinearads,omitempty"`
 }
 
+// UniversalAdID identifies a creative across systems
+type UniversalAdID struct {
+	// The URL of the registry website where the creative identifier is cataloged
+	IDRegistry string `xml:"idRegistry,attr,omitempty" json:"id_registry,omitempty"`
+	// The creative identifier (VAST 4.0 only, moved to the element value in 4.1)
+	IDValue string `xml:"idValue,attr,omitempty" json:"id_value,omitempty"`
+	ID      string `xml:",chardata" json:"id,omitempty"`
+}
+
 // CompanionAds contains companions creatives
 type CompanionAds struct {
 	// Provides information about which companion creative to display.
@@ -212,19 +323,27 @@ type Linear struct {
 	// begins playing.
 	SkipOffset *Offset `xml:"skipoffset,attr,omitempty" json:"skip_offset,omitempty"`
 	// Duration in standard time format, hh:mm:ss
-	Duration           *Duration           `json:"duration,omitempty"`
-	AdParameters       *AdParameters       `xml:",omitempty" json:"ad_parameters,omitempty"`
-	Icons              []*Icon             `json:"icons,omitempty"`
-	TrackingEvents     []*Tracking         `xml:"TrackingEvents>Tracking,omitempty" json:"tracking_events,omitempty"`
-	VideoClicks        *VideoClicks        `xml:",omitempty" json:"video_click,omitempty"`
-	MediaFiles         []*MediaFile        `xml:"MediaFiles>MediaFile,omitempty" json:"media_files,omitempty"`
-	CreativeExtensions *CreativeExtensions `xml:",omitempty" json:"creative_extension,omitempty"`
+	Duration       *Duration     `json:"duration,omitempty"`
+	AdParameters   *AdParameters `xml:",omitempty" json:"ad_parameters,omitempty"`
+	Icons          []*Icon       `xml:"Icons>Icon,omitempty" json:"icons,omitempty"`
+	TrackingEvents []*Tracking   `xml:"TrackingEvents>Tracking,omitempty" json:"tracking_events,omitempty"`
+	VideoClicks    *VideoClicks  `xml:",omitempty" json:"video_click,omitempty"`
+	MediaFiles     []*MediaFile  `xml:"MediaFiles>MediaFile,omitempty" json:"media_files,omitempty"`
+	// The raw, high quality media file used by ad servers to transcode the
+	// creative (VAST 4.0)
+	Mezzanines []*Mezzanine `xml:"MediaFiles>Mezzanine,omitempty" json:"mezzanines,omitempty"`
+	// The interactive part of the creative, separated from the media
+	// files (VAST 4.0)
+	InteractiveCreativeFiles []*InteractiveCreativeFile `xml:"MediaFiles>InteractiveCreativeFile,omitempty" json:"interactive_creative_files,omitempty"`
+	// Closed caption files matching the media files (VAST 4.1)
+	ClosedCaptionFiles []*ClosedCaptionFile `xml:"MediaFiles>ClosedCaptionFiles>ClosedCaptionFile,omitempty" json:"closed_caption_files,omitempty"`
+	CreativeExtensions *CreativeExtensions  `xml:",omitempty" json:"creative_extension,omitempty"`
 }
 
 // LinearWrapper defines a wrapped linear creative
 type LinearWrapper struct {
-	Icons              []*Icon             `json:"icons,omitempty"`
-	TrackingEvents     []*Tracking         `xml:"TrackingEvents>Tracking,omitempty" josn:"tracking_events,omitempty"`
+	Icons              []*Icon             `xml:"Icons>Icon,omitempty" json:"icons,omitempty"`
+	TrackingEvents     []*Tracking         `xml:"TrackingEvents>Tracking,omitempty" json:"tracking_events,omitempty"`
 	VideoClicks        *VideoClicks        `xml:",omitempty" json:"video_click,omitempty"`
 	CreativeExtensions *CreativeExtensions `xml:",omitempty" json:"creative_extension,omitempty"`
 }
@@ -251,6 +370,8 @@ type Companion struct {
 	AdSlotID string `xml:"adSlotId,attr,omitempty" json:"ad_slot_id,omitempty"`
 	// URL to open as destination page when user clicks on the the companion banner ad.
 	CompanionClickThrough string `xml:",omitempty" json:"companion_click_through,omitempty"`
+	// URLs to ping when user clicks on the the companion banner ad.
+	CompanionClickTracking []string `xml:",omitempty" json:"companion_click_trackings,omitempty"`
 	// Alt text to be displayed when companion is rendered in HTML environment.
 	AltText string `xml:",omitempty" json:"alt_text,omitempty"`
 	// The creativeView should always be requested when present. For Companions
@@ -364,7 +485,7 @@ type NonLinearWrapper struct {
 	MaintainAspectRatio bool `xml:"maintainAspectRatio,attr,omitempty" json:"maintain_aspect_ratio,omitempty"`
 	// Suggested duration to display non-linear ad, typically for animation to complete.
 	// Expressed in standard time format hh:mm:ss.
-	MinSuggestedDuration *Duration `xml:"minSuggestedDuration,attr,omitempty" json"min_suggested_duration,omitempty"`
+	MinSuggestedDuration *Duration `xml:"minSuggestedDuration,attr,omitempty" json:"min_suggested_duration,omitempty"`
 	// The apiFramework defines the method to use for communication with the nonlinear element.
 	APIFramework string `xml:"apiFramework,attr,omitempty" json:"api_framework,omitempty"`
 	// The creativeView should always be requested when present.
@@ -387,7 +508,7 @@ type Icon struct {
 	XPosition string `xml:"xPosition,attr" json:"x_position,omitempty"`
 	// The vertical alignment location (in pixels) or a specific alignment.
 	// Must match ([0-9]*|top|bottom)
-	YPosition string `xml:"xPosition,attr" json:"y_position,omitempty"`
+	YPosition string `xml:"yPosition,attr" json:"y_position,omitempty"`
 	// Start time at which the player should display the icon. Expressed in standard time format hh:mm:ss.
 	Offset *Offset `xml:"offset,attr" json:"offset,omitempty"`
 	// duration for which the player must display the icon. Expressed in standard time format hh:mm:ss.
@@ -398,6 +519,8 @@ type Icon struct {
 	IconClickThrough string `xml:"IconClicks>IconClickThrough,omitempty" json:"icon_click_through,omitempty"`
 	// URLs to ping when user clicks on the the icon.
 	IconClickTrackings []string `xml:"IconClicks>IconClickTracking,omitempty" json:"icon_click_trackings,omitempty"`
+	// URLs to ping when the icon is displayed.
+	IconViewTrackings []string `xml:"IconViewTracking,omitempty" json:"icon_view_trackings,omitempty"`
 	// URL to a static file, such as an image or SWF file
 	StaticResource *StaticResource `xml:",omitempty" json:"static_resource,omitempty"`
 	// URL source for an IFrame to display the companion element
@@ -413,7 +536,8 @@ type Tracking struct {
 	//
 	// Possible values are creativeView, start, firstQuartile, midpoint, thirdQuartile,
 	// complete, mute, unmute, pause, rewind, resume, fullscreen, exitFullscreen, expand,
-	// collapse, acceptInvitation, close, skip, progress.
+	// collapse, acceptInvitation, close, skip, progress and the other TrackingEvent
+	// constants. The TrackingEvent method returns the event as a TrackingEvent.
 	Event string `xml:"event,attr" json:"event,omitempty"`
 	// The time during the video at which this url should be pinged. Must be present for
 	// progress event. Must match (\d{2}:[0-5]\d:[0-5]\d(\.\d\d\d)?|1?\d?\d(\.?\d)*%)
@@ -494,9 +618,51 @@ type MediaFile struct {
 	URI          string `xml:",chardata" json:"url,omitempty"`
 }
 
+// Mezzanine defines the raw, high quality media file of a linear creative
+type Mezzanine struct {
+	// Optional identifier
+	ID string `xml:"id,attr,omitempty" json:"id,omitempty"`
+	// Method of delivery of ad (either "streaming" or "progressive")
+	Delivery string `xml:"delivery,attr" json:"delivery,omitempty"`
+	// MIME type of the file
+	Type string `xml:"type,attr" json:"type,omitempty"`
+	// Pixel dimensions of video.
+	Width int `xml:"width,attr" json:"width,omitempty"`
+	// Pixel dimensions of video.
+	Height int `xml:"height,attr" json:"height,omitempty"`
+	// The codec used to produce the file.
+	Codec string `xml:"codec,attr,omitempty" json:"codec,omitempty"`
+	// Size of the file in bytes
+	FileSize int `xml:"fileSize,attr,omitempty" json:"file_size,omitempty"`
+	// The type of media of the file, "2D", "3D" or "360"
+	MediaType string `xml:"mediaType,attr,omitempty" json:"media_type,omitempty"`
+	URI       string `xml:",chardata" json:"url,omitempty"`
+}
+
+// InteractiveCreativeFile defines the executable asset of an interactive
+// linear creative
+type InteractiveCreativeFile struct {
+	// MIME type of the file
+	Type string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	// The API framework needed to execute the file (e.g. "SIMID")
+	APIFramework string `xml:"apiFramework,attr,omitempty" json:"api_framework,omitempty"`
+	// Whether the interactive creative may change the duration of the ad
+	VariableDuration bool   `xml:"variableDuration,attr,omitempty" json:"variable_duration,omitempty"`
+	URI              string `xml:",chardata" json:"url,omitempty"`
+}
+
+// ClosedCaptionFile is a closed caption file for a linear creative
+type ClosedCaptionFile struct {
+	// MIME type of the file (e.g. "text/vtt")
+	Type string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	// The language of the captions as a ISO 639 code
+	Language string `xml:"language,attr,omitempty" json:"language,omitempty"`
+	URI      string `xml:",chardata" json:"url,omitempty"`
+}
+
 // Extensions defines extensions
 type Extensions struct {
-	Extensions []*Extension `xml:",omitempty" json:"extensions,omitempty"`
+	Extensions []*Extension `xml:"Extension,omitempty" json:"extensions,omitempty"`
 }
 
 // CreativeExtensions defines extensions for creatives
@@ -505,6 +671,15 @@ type CreativeExtensions struct {
 }
 
 // Extension represent aribtrary XML provided by the platform to extend the VAST response
+//
+// Extensions of a registered type can be decoded into a typed value with Decode.
 type Extension struct {
-	Data []byte `xml:",innerxml" json:"data,omitempty"`
+	// The element name, either Extension or CreativeExtension
+	XMLName xml.Name `json:"-"`
+	// The type of the extension, used to select the decoder registered with
+	// RegisterExtension
+	Type string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	// The other attributes of the extension element
+	Attrs []xml.Attr `xml:",any,attr" json:"-"`
+	Data  []byte     `xml:",innerxml" json:"data,omitempty"`
 }
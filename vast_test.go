@@ -310,3 +310,124 @@ func TestWrapperNonLinear(t *testing.T) {
 		}
 	}
 }
+
+func TestInlineLinearVAST4(t *testing.T) {
+	v, err := loadFixture("testdata/vast4_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "4.1", v.Version)
+	if assert.Len(t, v.Ads, 1) {
+		ad := v.Ads[0]
+		assert.Equal(t, 1, ad.Sequence)
+		if assert.NotNil(t, ad.InLine) {
+			inline := ad.InLine
+			assert.Equal(t, "a532d16d-4d7f-4440-bd29-2ec0e693fc80", inline.AdServingID)
+			if assert.Len(t, inline.Categories, 2) {
+				assert.Equal(t, "https://www.iabtechlab.com/categoryauthority", inline.Categories[0].Authority)
+				assert.Equal(t, "IAB1-1", inline.Categories[0].Code)
+			}
+			assert.Equal(t, 3600, inline.Expires)
+			if assert.NotNil(t, inline.Pricing) {
+				assert.Equal(t, "cpm", inline.Pricing.Model)
+				assert.Equal(t, "USD", inline.Pricing.Currency)
+				price, err := inline.Pricing.Float()
+				assert.NoError(t, err)
+				assert.Equal(t, 25.0, price)
+			}
+			if assert.NotNil(t, inline.ViewableImpression) {
+				assert.Equal(t, "1543", inline.ViewableImpression.ID)
+				assert.Equal(t, []string{"https://example.com/viewable"}, inline.ViewableImpression.Viewable)
+				assert.Equal(t, []string{"https://example.com/notviewable"}, inline.ViewableImpression.NotViewable)
+				assert.Equal(t, []string{"https://example.com/undetermined"}, inline.ViewableImpression.ViewUndetermined)
+			}
+			if assert.Len(t, inline.AdVerifications, 1) {
+				ver := inline.AdVerifications[0]
+				assert.Equal(t, "company.com-omid", ver.Vendor)
+				if assert.Len(t, ver.JavaScriptResources, 1) {
+					assert.Equal(t, "omid", ver.JavaScriptResources[0].APIFramework)
+					assert.True(t, ver.JavaScriptResources[0].BrowserOptional)
+					assert.Equal(t, "https://verificationcompany.com/omid.js", ver.JavaScriptResources[0].URI)
+				}
+				if assert.Len(t, ver.TrackingEvents, 1) {
+					assert.Equal(t, "verificationNotExecuted", ver.TrackingEvents[0].Event)
+				}
+				assert.Equal(t, `{"key":"value"}`, ver.VerificationParameters)
+			}
+			if assert.Len(t, inline.Creatives, 1) {
+				crea := inline.Creatives[0]
+				assert.Equal(t, "2447226", crea.AdID)
+				if assert.Len(t, crea.UniversalAdIDs, 2) {
+					assert.Equal(t, "Ad-ID", crea.UniversalAdIDs[0].IDRegistry)
+					assert.Equal(t, "8465", crea.UniversalAdIDs[0].ID)
+					assert.Equal(t, "clearcast.co.uk", crea.UniversalAdIDs[1].IDRegistry)
+					assert.Equal(t, "CNT/LVIS123/030", crea.UniversalAdIDs[1].ID)
+				}
+				if assert.NotNil(t, crea.Linear) {
+					linear := crea.Linear
+					assert.Len(t, linear.MediaFiles, 1)
+					if assert.Len(t, linear.Mezzanines, 1) {
+						assert.Equal(t, 40000000, linear.Mezzanines[0].FileSize)
+						assert.Equal(t, "2D", linear.Mezzanines[0].MediaType)
+						assert.Equal(t, "https://example.com/media/mezzanine.mp4", linear.Mezzanines[0].URI)
+					}
+					if assert.Len(t, linear.InteractiveCreativeFiles, 1) {
+						assert.Equal(t, "SIMID", linear.InteractiveCreativeFiles[0].APIFramework)
+						assert.True(t, linear.InteractiveCreativeFiles[0].VariableDuration)
+					}
+					if assert.Len(t, linear.ClosedCaptionFiles, 2) {
+						assert.Equal(t, "fr", linear.ClosedCaptionFiles[1].Language)
+						assert.Equal(t, "https://example.com/captions-fr.vtt", linear.ClosedCaptionFiles[1].URI)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestWrapperVAST4(t *testing.T) {
+	v, err := loadFixture("testdata/vast4_wrapper.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, v.Ads, 1) && assert.NotNil(t, v.Ads[0].Wrapper) {
+		wrapper := v.Ads[0].Wrapper
+		if assert.NotNil(t, wrapper.FollowAdditionalWrappers) {
+			assert.False(t, *wrapper.FollowAdditionalWrappers)
+		}
+		if assert.NotNil(t, wrapper.AllowMultipleAds) {
+			assert.True(t, *wrapper.AllowMultipleAds)
+		}
+		if assert.NotNil(t, wrapper.FallbackOnNoAd) {
+			assert.True(t, *wrapper.FallbackOnNoAd)
+		}
+		if assert.NotNil(t, wrapper.ViewableImpression) {
+			assert.Equal(t, []string{"https://example.com/wrapper/viewable"}, wrapper.ViewableImpression.Viewable)
+		}
+		if assert.Len(t, wrapper.AdVerifications, 1) {
+			assert.Equal(t, "other.com-omid", wrapper.AdVerifications[0].Vendor)
+		}
+		if assert.Len(t, wrapper.BlockedAdCategories, 1) {
+			assert.Equal(t, "IAB8-5,IAB8-18", wrapper.BlockedAdCategories[0].Categories)
+		}
+	}
+}
+
+func TestRoundTripVAST4(t *testing.T) {
+	for _, path := range []string{"testdata/vast4_inline_linear.xml", "testdata/vast4_wrapper.xml"} {
+		v, err := loadFixture(path)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		b, err := xml.Marshal(v)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		var v2 VAST
+		if assert.NoError(t, xml.Unmarshal(b, &v2)) {
+			assert.Equal(t, v, &v2, path)
+		}
+	}
+}
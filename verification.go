@@ -0,0 +1,114 @@
+package vast
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MacroReason is the name of the macro set by Macros.SetReason.
+const MacroReason = "REASON"
+
+// VerificationReason tells why a verification resource was not executed, as
+// substituted to the [REASON] macro of verificationNotExecuted trackers.
+type VerificationReason int
+
+// Reasons defined by VAST 4.1 for the verificationNotExecuted event.
+const (
+	// The player did not execute the resource because of its own policy
+	VerificationRejected VerificationReason = 1
+	// The API framework or language of the resource is not supported
+	VerificationNotSupported VerificationReason = 2
+	// The resource could not be loaded
+	VerificationLoadError VerificationReason = 3
+)
+
+// SetReason sets the [REASON] macro.
+func (m *Macros) SetReason(reason VerificationReason) {
+	m.Set(MacroReason, strconv.Itoa(int(reason)))
+}
+
+// Verifications returns the Open Measurement verifications of the ad, whether
+// they are provided by the VAST 4.1 AdVerifications element of the InLine or
+// Wrapper, or by an <Extension type="AdVerifications"> as done in VAST 3.
+// Verifications with the same vendor and resources are returned once.
+//
+// As MergeWrapper adds the verifications of the wrapper to the InLine, the
+// verifications of a whole wrapper chain are returned by calling Verifications
+// on the ad returned by ResolvedAd.Flatten, the InLine ones first.
+func (ad *Ad) Verifications() []*Verification {
+	var vers []*Verification
+	if ad.InLine != nil {
+		vers = appendVerifications(vers, ad.InLine.AdVerifications)
+		vers = appendVerifications(vers, extensionVerifications(ad.InLine.Extensions))
+	}
+	if ad.Wrapper != nil {
+		vers = appendVerifications(vers, ad.Wrapper.AdVerifications)
+		vers = appendVerifications(vers, extensionVerifications(ad.Wrapper.Extensions))
+	}
+	return vers
+}
+
+// appendVerifications appends to vers the verifications of add which are not
+// already in it, as identified by their vendor and resource URLs.
+func appendVerifications(vers, add []*Verification) []*Verification {
+	seen := make(map[string]bool, len(vers)+len(add))
+	for _, v := range vers {
+		seen[v.key()] = true
+	}
+	for _, v := range add {
+		if k := v.key(); !seen[k] {
+			seen[k] = true
+			vers = append(vers, v)
+		}
+	}
+	return vers
+}
+
+// key identifies the verification by its vendor and resource URLs
+func (v *Verification) key() string {
+	k := []string{v.Vendor}
+	for _, r := range v.JavaScriptResources {
+		k = append(k, strings.TrimSpace(r.URI))
+	}
+	for _, r := range v.ExecutableResources {
+		k = append(k, strings.TrimSpace(r.URI))
+	}
+	return strings.Join(k, "\n")
+}
+
+// extensionVerifications returns the verifications of the AdVerifications
+// extensions found in exts. Extensions which can't be decoded are ignored.
+func extensionVerifications(exts *Extensions) []*Verification {
+	if exts == nil {
+		return nil
+	}
+	var vers []*Verification
+	for _, e := range exts.Extensions {
+		if e.Type != "AdVerifications" {
+			continue
+		}
+		v, err := e.Decode()
+		if err != nil {
+			continue
+		}
+		if av, ok := v.(*AdVerificationsExtension); ok {
+			vers = append(vers, av.Verifications...)
+		}
+	}
+	return vers
+}
+
+// NotExecutedURIs returns the URIs of the verificationNotExecuted trackers of
+// the verification with the [REASON] macro set to reason. Other macros are
+// expanded using m, which may be nil.
+func (v *Verification) NotExecutedURIs(reason VerificationReason, m *Macros) []string {
+	vm := m.clone()
+	vm.SetReason(reason)
+	var uris []string
+	for _, t := range v.TrackingEvents {
+		if t.TrackingEvent() == EventVerificationNotExecuted {
+			uris = append(uris, vm.Expand(t.URI))
+		}
+	}
+	return uris
+}
@@ -0,0 +1,70 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdVerifications(t *testing.T) {
+	v, err := loadFixture("testdata/vast_extensions.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	vers := v.Ads[0].Verifications()
+	if assert.Len(t, vers, 1) {
+		assert.Equal(t, "company.com-omid", vers[0].Vendor)
+		assert.Equal(t, "omid", vers[0].JavaScriptResources[0].APIFramework)
+		assert.Equal(t, `{"key":"value"}`, vers[0].VerificationParameters)
+	}
+
+	v4, err := loadFixture("testdata/vast4_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	vers = v4.Ads[0].Verifications()
+	if assert.Len(t, vers, 1) {
+		assert.Equal(t, "company.com-omid", vers[0].Vendor)
+	}
+}
+
+func TestVerificationsWrapperChain(t *testing.T) {
+	w, err := loadFixture("testdata/vast4_wrapper.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	in, err := loadFixture("testdata/vast_extensions.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// A VAST 3 wrapper carrying its verification in an extension
+	w3 := &Wrapper{Extensions: in.Ads[0].InLine.Extensions}
+
+	r := &ResolvedAd{Ad: in.Ads[0], Wrappers: []*Ad{w.Ads[0], {Wrapper: w3}}}
+	var vendors []string
+	for _, ver := range r.Flatten().Verifications() {
+		vendors = append(vendors, ver.Vendor)
+	}
+	// The InLine verification comes first and the one repeated by w3 is dropped
+	assert.Equal(t, []string{"company.com-omid", "other.com-omid"}, vendors)
+	assert.Len(t, in.Ads[0].InLine.AdVerifications, 0)
+
+	// The same vendor with another resource is kept
+	other := &Verification{Vendor: "company.com-omid", JavaScriptResources: []*JavaScriptResource{{URI: "https://other.cdn/omid.js"}}}
+	m := MergeWrapper(&Wrapper{AdVerifications: []*Verification{other}}, in.Ads[0].InLine)
+	assert.Len(t, (&Ad{InLine: m}).Verifications(), 2)
+}
+
+func TestVerificationNotExecutedURIs(t *testing.T) {
+	v, err := loadFixture("testdata/vast_extensions.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ver := v.Ads[0].Verifications()[0]
+	var m Macros
+	m.SetCacheBusting(1)
+	assert.Equal(t, []string{"https://verificationcompany.com/notexecuted?reason=2"}, ver.NotExecutedURIs(VerificationNotSupported, &m))
+	assert.Equal(t, []string{"https://verificationcompany.com/notexecuted?reason=3"}, ver.NotExecutedURIs(VerificationLoadError, nil))
+	_, found := m.Get(MacroReason)
+	assert.False(t, found)
+}
@@ -0,0 +1,149 @@
+package vast
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VMAPNamespace is the XML namespace of VMAP elements
+const VMAPNamespace = "http://www.iab.net/videosuite/vmap"
+
+// VMAP is the root <vmap:VMAP> tag of an IAB VMAP 1.0 document
+// http://www.iab.net/media/file/VMAP.pdf
+type VMAP struct {
+	XMLName xml.Name `xml:"http://www.iab.net/videosuite/vmap VMAP" json:"-"`
+	// The version of the VMAP spec (should be "1.0")
+	Version string `xml:"version,attr" json:"version,omitempty"`
+	// The ad breaks of the content, with the ads to play in each of them
+	AdBreaks []*AdBreak `xml:"http://www.iab.net/videosuite/vmap AdBreak" json:"ad_breaks,omitempty"`
+	// Custom extensions
+	Extensions []*Extension `xml:"http://www.iab.net/videosuite/vmap Extensions>Extension,omitempty" json:"extensions,omitempty"`
+}
+
+// AdBreak is a single ad break opportunity within the content timeline
+type AdBreak struct {
+	// The position of the ad break in the content
+	TimeOffset TimeOffset `xml:"timeOffset,attr" json:"time_offset"`
+	// The allowed types of ads for the break, a comma separated list of
+	// "linear", "nonlinear" and "display"
+	BreakType string `xml:"breakType,attr" json:"break_type,omitempty"`
+	// An optional identifier for the ad break
+	BreakID string `xml:"breakId,attr,omitempty" json:"break_id,omitempty"`
+	// When set, the ad break repeats every given duration after its first
+	// occurrence
+	RepeatAfter *Duration `xml:"repeatAfter,attr,omitempty" json:"repeat_after,omitempty"`
+	// The ads to play during the break
+	AdSource *AdSource `xml:"http://www.iab.net/videosuite/vmap AdSource,omitempty" json:"ad_source,omitempty"`
+	// The breakStart, breakEnd and error tracking events of the break
+	TrackingEvents []*Tracking `xml:"http://www.iab.net/videosuite/vmap TrackingEvents>Tracking,omitempty" json:"tracking_events,omitempty"`
+	// Custom extensions
+	Extensions []*Extension `xml:"http://www.iab.net/videosuite/vmap Extensions>Extension,omitempty" json:"extensions,omitempty"`
+}
+
+// AdSource provides the ads of an ad break, either inline as a VAST document,
+// as a URI to an ad server or as custom ad data.
+type AdSource struct {
+	// An optional identifier for the ad source
+	ID string `xml:"id,attr,omitempty" json:"id,omitempty"`
+	// Whether the VAST response may contain ad pods or multiple buffet ads
+	AllowMultipleAds *bool `xml:"allowMultipleAds,attr,omitempty" json:"allow_multiple_ads,omitempty"`
+	// Whether VAST wrappers should be followed
+	FollowRedirects *bool `xml:"followRedirects,attr,omitempty" json:"follow_redirects,omitempty"`
+	// A VAST document embedded in the VMAP document
+	VASTAdData *VASTAdData `xml:"http://www.iab.net/videosuite/vmap VASTAdData,omitempty" json:"vast_ad_data,omitempty"`
+	// A URI to the ad response
+	AdTagURI *AdTagURI `xml:"http://www.iab.net/videosuite/vmap AdTagURI,omitempty" json:"ad_tag_uri,omitempty"`
+	// An ad response in a format other than VAST
+	CustomAdData *CustomAdData `xml:"http://www.iab.net/videosuite/vmap CustomAdData,omitempty" json:"custom_ad_data,omitempty"`
+}
+
+// VASTAdData holds a VAST document embedded in an ad source
+type VASTAdData struct {
+	VAST *VAST `xml:"VAST" json:"vast,omitempty"`
+}
+
+// AdTagURI is the URI of the ad response of an ad source
+type AdTagURI struct {
+	// The format of the ad response, e.g. "vast3"
+	TemplateType string `xml:"templateType,attr" json:"template_type,omitempty"`
+	URI          string `xml:",chardata" json:"url,omitempty"`
+}
+
+// CustomAdData is an ad response in an arbitrary format
+type CustomAdData struct {
+	// The format of the ad response
+	TemplateType string `xml:"templateType,attr" json:"template_type,omitempty"`
+	Data         []byte `xml:",innerxml" json:"data,omitempty"`
+}
+
+// TimeOffset is the position of an ad break in the content. It is either the
+// start or the end of the content, a time or percent offset, or the position
+// of the break among the content's cue points.
+type TimeOffset struct {
+	// The break is at the start of the content ("start")
+	Start bool
+	// The break is at the end of the content ("end")
+	End bool
+	// If not nil, the offset of the break ("hh:mm:ss.mmm" or "n%")
+	Offset *Offset
+	// If not zero, the 1 based position of the break among the cue points of
+	// the content ("#n")
+	Position int
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (t TimeOffset) MarshalText() ([]byte, error) {
+	switch {
+	case t.Start:
+		return []byte("start"), nil
+	case t.End:
+		return []byte("end"), nil
+	case t.Position > 0:
+		return []byte("#" + strconv.Itoa(t.Position)), nil
+	case t.Offset != nil:
+		return t.Offset.MarshalText()
+	}
+	return nil, fmt.Errorf("invalid time offset: empty")
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (t *TimeOffset) UnmarshalText(data []byte) error {
+	*t = TimeOffset{}
+	s := strings.TrimSpace(string(data))
+	switch {
+	case s == "start":
+		t.Start = true
+	case s == "end":
+		t.End = true
+	case strings.HasPrefix(s, "#"):
+		n, err := strconv.Atoi(s[1:])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid time offset: %s", data)
+		}
+		t.Position = n
+	default:
+		var o Offset
+		if err := o.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid time offset: %s", data)
+		}
+		t.Offset = &o
+	}
+	return nil
+}
+
+// Resolve returns the time at which the break must be played in a content of
+// the given duration. Positional breaks can't be resolved and return false.
+func (t TimeOffset) Resolve(content time.Duration) (time.Duration, bool) {
+	switch {
+	case t.Start:
+		return 0, true
+	case t.End:
+		return content, true
+	case t.Offset != nil:
+		return t.Offset.Resolve(content), true
+	}
+	return 0, false
+}
@@ -0,0 +1,138 @@
+package vast
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loadVMAPFixture(path string) (*VMAP, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var v VMAP
+	err = xml.Unmarshal(b, &v)
+	return &v, err
+}
+
+func TestVMAP(t *testing.T) {
+	v, err := loadVMAPFixture("testdata/vmap.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "1.0", v.Version)
+	if !assert.Len(t, v.AdBreaks, 5) {
+		return
+	}
+
+	pre := v.AdBreaks[0]
+	assert.True(t, pre.TimeOffset.Start)
+	assert.Equal(t, "linear", pre.BreakType)
+	assert.Equal(t, "preroll", pre.BreakID)
+	if assert.NotNil(t, pre.AdSource) {
+		assert.Equal(t, "preroll-ad-1", pre.AdSource.ID)
+		if assert.NotNil(t, pre.AdSource.AllowMultipleAds) {
+			assert.False(t, *pre.AdSource.AllowMultipleAds)
+		}
+		if assert.NotNil(t, pre.AdSource.FollowRedirects) {
+			assert.True(t, *pre.AdSource.FollowRedirects)
+		}
+		if assert.NotNil(t, pre.AdSource.VASTAdData) && assert.Len(t, pre.AdSource.VASTAdData.VAST.Ads, 1) {
+			ad := pre.AdSource.VASTAdData.VAST.Ads[0]
+			assert.Equal(t, "601364", ad.ID)
+			assert.Equal(t, Duration(15*time.Second), *ad.InLine.Creatives[0].Linear.Duration)
+		}
+		assert.Nil(t, pre.AdSource.AdTagURI)
+	}
+	if assert.Len(t, pre.TrackingEvents, 2) {
+		assert.Equal(t, "breakStart", pre.TrackingEvents[0].Event)
+		assert.Equal(t, "http://example.com/breakStart?id=preroll", pre.TrackingEvents[0].URI)
+	}
+
+	mid := v.AdBreaks[1]
+	if assert.NotNil(t, mid.TimeOffset.Offset) {
+		assert.Equal(t, Duration(10*time.Minute+500*time.Millisecond), *mid.TimeOffset.Offset.Duration)
+	}
+	assert.Equal(t, Duration(10*time.Minute), *mid.RepeatAfter)
+	if assert.NotNil(t, mid.AdSource.AdTagURI) {
+		assert.Equal(t, "vast3", mid.AdSource.AdTagURI.TemplateType)
+		assert.Equal(t, "http://example.com/vast?pos=midroll", mid.AdSource.AdTagURI.URI)
+	}
+
+	display := v.AdBreaks[2]
+	if assert.NotNil(t, display.TimeOffset.Offset) {
+		assert.Equal(t, float32(.5), display.TimeOffset.Offset.Percent)
+	}
+	if assert.NotNil(t, display.AdSource.CustomAdData) {
+		assert.Equal(t, `<ad id="custom"/>`, string(display.AdSource.CustomAdData.Data))
+	}
+
+	assert.Equal(t, 2, v.AdBreaks[3].TimeOffset.Position)
+	assert.True(t, v.AdBreaks[4].TimeOffset.End)
+}
+
+func TestVMAPRoundTrip(t *testing.T) {
+	v, err := loadVMAPFixture("testdata/vmap.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, err := xml.Marshal(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var v2 VMAP
+	if assert.NoError(t, xml.Unmarshal(b, &v2)) {
+		assert.Equal(t, v, &v2)
+	}
+}
+
+func TestVMAPNamespace(t *testing.T) {
+	var v VMAP
+	err := xml.Unmarshal([]byte(`<vmap:VMAP xmlns:vmap="http://www.iab.net/videosuite/vmap" version="1.0">
+		<vmap:AdBreak timeOffset="start" breakType="linear"><vmap:AdSource><VASTAdData><VAST version="3.0"></VAST></VASTAdData></vmap:AdSource></vmap:AdBreak>
+	</vmap:VMAP>`), &v)
+	if assert.NoError(t, err) && assert.Len(t, v.AdBreaks, 1) && assert.NotNil(t, v.AdBreaks[0].AdSource) {
+		assert.Nil(t, v.AdBreaks[0].AdSource.VASTAdData)
+	}
+}
+
+func TestTimeOffset(t *testing.T) {
+	content := 40 * time.Minute
+	for _, tc := range []struct {
+		text   string
+		at     time.Duration
+		timed  bool
+		errMsg string
+	}{
+		{"start", 0, true, ""},
+		{"end", content, true, ""},
+		{"00:10:00.500", 10*time.Minute + 500*time.Millisecond, true, ""},
+		{"25%", 10 * time.Minute, true, ""},
+		{"#3", 0, false, ""},
+		{"#0", 0, false, "invalid time offset: #0"},
+		{"middle", 0, false, "invalid time offset: middle"},
+	} {
+		var o TimeOffset
+		err := o.UnmarshalText([]byte(tc.text))
+		if tc.errMsg != "" {
+			assert.EqualError(t, err, tc.errMsg)
+			continue
+		}
+		if !assert.NoError(t, err, tc.text) {
+			continue
+		}
+		at, timed := o.Resolve(content)
+		assert.Equal(t, tc.timed, timed, tc.text)
+		assert.Equal(t, tc.at, at, tc.text)
+		b, err := o.MarshalText()
+		if assert.NoError(t, err) {
+			assert.Equal(t, tc.text, string(b))
+		}
+	}
+	_, err := TimeOffset{}.MarshalText()
+	assert.EqualError(t, err, "invalid time offset: empty")
+}